@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service"
+)
+
+// runTeam - подкоманды team add и team get
+func runTeam(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			fmt.Fprintln(os.Stderr, "usage: team add <team_name> <user_id:username[:inactive]>...")
+			return 2
+		}
+
+		team := domain.Team{TeamName: args[1]}
+		for _, spec := range args[2:] {
+			member, err := parseMemberSpec(spec)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			team.Members = append(team.Members, member)
+		}
+
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			created, err := services.TeamService.CreateTeam(ctx, team)
+			if err != nil {
+				return err
+			}
+			return printJSON(map[string]interface{}{"team": created})
+		})
+	case "get":
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			team, err := services.TeamService.GetTeam(ctx, args[1])
+			if err != nil {
+				return err
+			}
+			return printJSON(team)
+		})
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+}
+
+// parseMemberSpec разбирает участника в виде user_id:username[:inactive]
+func parseMemberSpec(spec string) (domain.TeamMember, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return domain.TeamMember{}, fmt.Errorf("invalid member %q, expected user_id:username[:inactive]", spec)
+	}
+
+	member := domain.TeamMember{UserID: parts[0], Username: parts[1], IsActive: true}
+	if len(parts) == 3 {
+		if parts[2] != "inactive" {
+			return domain.TeamMember{}, fmt.Errorf("invalid member flag %q, expected inactive", parts[2])
+		}
+		member.IsActive = false
+	}
+	return member, nil
+}
+
+// runUser - подкоманды user activate и user deactivate.
+// Деактивация идет через тот же сценарий, что и /users/deactivate, с переназначением открытых PR
+func runUser(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "activate":
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			user, err := services.UserService.SetIsActive(ctx, args[1], true, nil)
+			if err != nil {
+				return err
+			}
+			return printJSON(map[string]interface{}{"user": user})
+		})
+	case "deactivate":
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			job, err := services.DeactivationJobs.RunBulkDeactivation(ctx, args[1:])
+			if err != nil {
+				return err
+			}
+			return printJSON(job)
+		})
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+}
+
+// runPullRequest - подкоманда pr reassign
+func runPullRequest(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) != 3 || args[0] != "reassign" {
+		fmt.Fprintln(os.Stderr, "usage: pr reassign <pull_request_id> <old_reviewer_id>")
+		return 2
+	}
+
+	return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+		pr, replacedBy, err := services.PullRequestService.ReassignReviewer(ctx, args[1], args[2], nil)
+		if err != nil {
+			return err
+		}
+		return printJSON(domain.ReassignResponse{PR: pr, ReplacedBy: replacedBy})
+	})
+}
+
+// runStats - подкоманда stats с теми же параметрами, что и /stats
+func runStats(cfg *config.Config, args []string, logger *slog.Logger) int {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	details := fs.Bool("details", false, "include per-user and per-PR assignments")
+	from := fs.String("from", "", "window start, RFC3339 or YYYY-MM-DD")
+	to := fs.String("to", "", "window end (exclusive), RFC3339 or YYYY-MM-DD")
+	teamName := fs.String("team", "", "team name")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	filter := domain.StatsFilter{TeamName: *teamName}
+	var err error
+	if filter.From, err = domain.ParseTime(*from); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -from:", err)
+		return 2
+	}
+	if filter.To, err = domain.ParseTime(*to); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid -to:", err)
+		return 2
+	}
+
+	return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+		stats, err := services.StatsService.GetStats(ctx, filter, *details)
+		if err != nil {
+			return err
+		}
+		return printJSON(map[string]interface{}{"stats": stats})
+	})
+}
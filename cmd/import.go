@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service"
+	"ynastt/avito_test_task_backend_2025/internal/service/team"
+)
+
+// runImport - подкоманда `import [-dry-run] [-format csv|yaml] <file>`.
+// Отчет печатается в stdout в формате JSON, код возврата ненулевой при ошибке или конфликтах
+func runImport(cfg *config.Config, args []string, logger *slog.Logger) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would change")
+	format := fs.String("format", "", "file format: csv or yaml (default: by file extension)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: import [-dry-run] [-format csv|yaml] <file>")
+		return 2
+	}
+
+	path := fs.Arg(0)
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			*format = domain.ImportFormatCSV
+		case ".yaml", ".yml":
+			*format = domain.ImportFormatYAML
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Error("failed to open import file", slog.Any("error", err))
+		return 1
+	}
+	defer file.Close()
+
+	records, err := team.ParseImport(file, *format)
+	if err != nil {
+		logger.Error("failed to parse import file", slog.Any("error", err))
+		return 1
+	}
+
+	return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+		report, err := services.TeamService.ImportTeams(ctx, records, *dryRun)
+		if report != nil {
+			if printErr := printJSON(report); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	})
+}
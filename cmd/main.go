@@ -2,124 +2,240 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 
-	"ynastt/avito_test_task_backend_2025/internal/handlers"
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/internal/domain"
 	"ynastt/avito_test_task_backend_2025/internal/repository"
 	"ynastt/avito_test_task_backend_2025/internal/service"
+	"ynastt/avito_test_task_backend_2025/internal/service/codeowners"
+	"ynastt/avito_test_task_backend_2025/internal/service/jobs"
+	"ynastt/avito_test_task_backend_2025/internal/service/outbox"
 	pr "ynastt/avito_test_task_backend_2025/internal/service/pullrequest"
+	"ynastt/avito_test_task_backend_2025/internal/service/snapshot"
 	"ynastt/avito_test_task_backend_2025/internal/service/team"
 	"ynastt/avito_test_task_backend_2025/internal/service/user"
+	"ynastt/avito_test_task_backend_2025/internal/service/vcs"
+	"ynastt/avito_test_task_backend_2025/internal/service/webhook"
 	"ynastt/avito_test_task_backend_2025/pkg/database"
-	"ynastt/avito_test_task_backend_2025/server"
 )
 
+const usage = `usage: main [command] [args]
+
+commands:
+  serve [-config file] [-host H] [-port P] [-log-level L] [-migrate auto|off|only]
+                                         run HTTP server (default)
+  migrate up|down [N]|status             manage database migrations
+  team add <team_name> <user_id:username[:inactive]>...
+  team get <team_name>
+  user activate <user_id>
+  user deactivate <user_id>...
+  pr reassign <pull_request_id> <old_reviewer_id>
+  stats [-details] [-from T] [-to T] [-team NAME]
+  import [-dry-run] [-format csv|yaml] <file>
+  snapshot export [-o file] | snapshot import <file>
+  webhook-stub [-addr host:port] [-secret S] [-fail N]
+                                         local webhook receiver for testing deliveries
+
+configuration: defaults < YAML file (-config or CONFIG_FILE) < environment < flags`
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Printf("warning: .env file not found: %v", err)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	command, args := "serve", []string(nil)
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command, args = os.Args[1], os.Args[2:]
+	} else if len(os.Args) > 1 {
+		args = os.Args[1:]
+	}
 
-	db, err := database.NewPostgresDB(database.Config{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		Username: os.Getenv("POSTGRES_USERNAME"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("DB_NAME"),
-		SSLMode:  os.Getenv("DB_SSL"),
-	}, logger)
-	if err != nil {
-		logger.Error("failed to initialize db", "error", err.Error())
-		os.Exit(1)
+	if len(os.Args) > 1 && (os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help") {
+		fmt.Println(usage)
+		return
 	}
 
-	// Миграция
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		log.Fatal("Migration driver error:", err)
+	// заглушке получателя вебхуков не нужны ни БД, ни конфигурация
+	if command == "webhook-stub" {
+		os.Exit(runWebhookStub(args, newLogger(os.Stderr, slog.LevelInfo)))
+	}
+
+	// флаги конфигурации принимает только serve, остальные подкоманды берут файл из CONFIG_FILE
+	var configArgs []string
+	if command == "serve" {
+		configArgs = args
 	}
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres", driver)
+	cfg, err := config.Load(configArgs)
 	if err != nil {
-		logger.Error("migrate init error", slog.Any("error", err))
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		logger.Error("migration error", slog.Any("error", err))
-		os.Exit(1)
+	level, _ := cfg.SlogLevel()
+
+	if command == "serve" {
+		os.Exit(runServe(cfg, newLogger(os.Stdout, level)))
 	}
 
-	defer func() {
-		if err := db.Close(); err != nil {
-			logger.Error("Error error occured on closing database connection", slog.Any("error", err))
-		} else {
-			logger.Info("Database connection closed gracefully")
-		}
-	}()
+	// остальные подкоманды работают без HTTP-сервера, stdout занят их выводом, поэтому логи идут в stderr
+	logger := newLogger(os.Stderr, level)
+
+	var code int
+	switch command {
+	case "migrate":
+		code = runMigrate(cfg, args, logger)
+	case "team":
+		code = runTeam(cfg, args, logger)
+	case "user":
+		code = runUser(cfg, args, logger)
+	case "pr":
+		code = runPullRequest(cfg, args, logger)
+	case "stats":
+		code = runStats(cfg, args, logger)
+	case "import":
+		code = runImport(cfg, args, logger)
+	case "snapshot":
+		code = runSnapshot(cfg, args, logger)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		code = 2
+	}
+	os.Exit(code)
+}
+
+func newLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: level,
+	}))
+}
+
+func closeDB(pool *pgxpool.Pool, logger *slog.Logger) {
+	pool.Close()
+	logger.Info("Database connection closed gracefully")
+}
 
-	dbInstance := database.NewDB(db)
-	txManager, err := database.NewTransactionManager(db)
+func newServices(cfg *config.Config, pool *pgxpool.Pool, logger *slog.Logger) (*service.Services, error) {
+	dbInstance := database.NewDB(pool)
+	txManager, err := database.NewTransactionManager(pool)
 	if err != nil {
-		logger.Error("error creating transaction manager", slog.Any("error", err))
-		os.Exit(1)
+		return nil, err
 	}
 
 	teamRepo := repository.NewTeamRepository(dbInstance)
 	userRepo := repository.NewUserRepository(dbInstance)
 	prRepo := repository.NewPullRequestRepository(dbInstance)
 	statsRepo := repository.NewStatsRepository(dbInstance)
+	snapshotRepo := repository.NewSnapshotRepository(dbInstance)
+	jobRepo := repository.NewJobRepository(dbInstance)
+	deactivationJobRepo := repository.NewDeactivationJobRepository(dbInstance)
+	outboxRepo := repository.NewOutboxRepository(dbInstance)
+	webhookRepo := repository.NewWebhookRepository(dbInstance)
+	identityRepo := repository.NewIdentityRepository(dbInstance)
+	codeOwnersRepo := repository.NewCodeOwnersRepository(dbInstance)
+
+	jobService := jobs.NewJobService(jobRepo, txManager, jobs.Config{
+		Workers:         cfg.Jobs.Workers,
+		PollInterval:    cfg.Jobs.PollInterval,
+		MaxAttempts:     cfg.Jobs.MaxAttempts,
+		RetryBackoff:    cfg.Jobs.RetryBackoff,
+		MaxRetryBackoff: cfg.Jobs.MaxRetryBackoff,
+		Lease:           cfg.Jobs.Lease,
+		DrainTimeout:    cfg.Jobs.DrainTimeout,
+	}, logger)
 
-	services := &service.Services{
-		TeamService:        team.NewTeamService(teamRepo, userRepo, txManager, logger),
-		UserService:        user.NewUserService(userRepo, prRepo, txManager, logger),
-		PullRequestService: pr.NewPullRequestService(prRepo, userRepo, txManager, logger),
+	webhookService := webhook.NewWebhookService(webhookRepo, jobService, txManager, cfg.Webhooks.Timeout, logger)
+
+	sinks, err := outbox.NewSinks(cfg.Outbox.Sinks, outbox.NewLogSink(logger), webhookService.Sink())
+	if err != nil {
+		return nil, err
+	}
+
+	relay := outbox.NewRelay(outboxRepo, txManager, sinks, outbox.Config{
+		PollInterval:    cfg.Outbox.PollInterval,
+		BatchSize:       cfg.Outbox.BatchSize,
+		PublishTimeout:  cfg.Outbox.PublishTimeout,
+		RetryBackoff:    cfg.Outbox.RetryBackoff,
+		MaxRetryBackoff: cfg.Outbox.MaxRetryBackoff,
+		Retention:       cfg.Outbox.Retention,
+	}, logger)
+
+	identityService := vcs.NewIdentityService(identityRepo, userRepo, logger)
+
+	var providers []vcs.VCSProvider
+	switch cfg.VCS.Writeback {
+	case config.WritebackGitHub:
+		providers = append(providers, vcs.NewGitHubClient(cfg.VCS.GitHub.APIURL, cfg.VCS.GitHub.APIToken, cfg.VCS.Timeout))
+	case config.WritebackFake:
+		providers = append(providers, vcs.NewFakeProvider(domain.VCSGitHub, logger))
+	}
+	writeback := vcs.NewWritebackService(identityService, jobService, logger, providers...)
+
+	codeOwnersService := codeowners.NewCodeOwnersService(codeOwnersRepo, teamRepo, identityService, txManager, logger)
+
+	prService := pr.NewPullRequestService(prRepo, userRepo, outboxRepo, writeback, codeOwnersService, txManager,
+		cfg.Reviewers.MaxPerPR, domain.ReviewerStrategy(cfg.Reviewers.Strategy), cfg.Reviewers.PairLookback, logger)
+
+	userService := user.NewUserService(userRepo, prRepo, teamRepo, outboxRepo, txManager, logger)
+	deactivationJobs := user.NewDeactivationJobService(userService, deactivationJobRepo, jobService, txManager, cfg.Jobs.DeactivationWorkers, logger)
+
+	// обработчики задач регистрируются до запуска исполнителей
+	jobService.Register(user.BulkDeactivationKind, deactivationJobs.Handle)
+	jobService.Register(webhook.DeliveryKind, webhookService.Deliver)
+	jobService.Register(vcs.WritebackKind, writeback.Handle)
+
+	return &service.Services{
+		TeamService:        team.NewTeamService(teamRepo, userRepo, userService, txManager, logger),
+		CodeOwnersService:  codeOwnersService,
+		UserService:        userService,
+		DeactivationJobs:   deactivationJobs,
+		JobService:         jobService,
+		OutboxRelay:        relay,
+		WebhookService:     webhookService,
+		PullRequestService: prService,
+		IdentityService:    identityService,
+		GitHubService:      vcs.NewGitHubService(cfg.VCS.GitHub.WebhookSecret, prService, identityService, logger),
+		GitLabService:      vcs.NewGitLabService(cfg.VCS.GitLab.WebhookToken, prService, identityService, logger),
 		StatsService:       service.NewStatsService(statsRepo, logger),
+		SnapshotService:    snapshot.NewSnapshotService(snapshotRepo, txManager, logger),
+		HealthService:      service.NewHealthService(pool, logger),
+	}, nil
+}
+
+// withServices открывает БД, собирает сервисы и выполняет fn.
+// Возвращает код завершения процесса
+func withServices(cfg *config.Config, logger *slog.Logger, fn func(ctx context.Context, services *service.Services) error) int {
+	ctx := context.Background()
+
+	pool, err := database.NewPostgresPool(ctx, cfg.Database, logger)
+	if err != nil {
+		return 1
+	}
+	defer closeDB(pool, logger)
+
+	services, err := newServices(cfg, pool, logger)
+	if err != nil {
+		logger.Error("error creating services", slog.Any("error", err))
+		return 1
 	}
 
-	handlers := handlers.NewHandler(services, logger)
-
-	srv := new(server.Server)
-	serverErrors := make(chan error, 1)
-	go func() {
-		if err := srv.Run(os.Getenv("SERVER_PORT"), handlers.InitRoutes()); err != nil {
-			serverErrors <- err
-		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
-
-	select {
-	case <-quit:
-		logger.Info("Gracefully Shutting Down")
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		if err := srv.Shutdown(ctx); err != nil {
-			logger.Error("Error occured on server shutting down", slog.Any("error", err))
-		}
-		// catching ctx.Done(). timeout of 5 seconds.
-		<-ctx.Done()
-		logger.Info("Timeout of 5 seconds.")
-
-		logger.Info("Server stopped gracefully")
-	case err := <-serverErrors:
-		logger.Error("Error occured while running server", slog.Any("error", err))
-		os.Exit(1)
+	if err := fn(ctx, services); err != nil {
+		logger.Error("command failed", slog.Any("error", err))
+		return 1
 	}
+	return 0
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/migrations"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+const migrateUsage = "usage: migrate up | migrate down [N] | migrate status"
+
+// ключ advisory-блокировки, под которой реплики применяют миграции по очереди
+const migrationLockKey int64 = 7_120_250_001
+
+// newMigrate читает миграции из встроенной в бинарник FS и использует пул приложения
+// через database/sql-обертку pgx, поэтому для миграций не нужен отдельный драйвер lib/pq.
+// Вызывающий должен закрыть результат через Close
+func newMigrate(pool *pgxpool.Pool) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migration source error: %w", err)
+	}
+
+	driver, err := pgxmigrate.WithInstance(stdlib.OpenDBFromPool(pool), &pgxmigrate.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("migration driver error: %w", err)
+	}
+
+	return migrate.NewWithInstance("iofs", source, "pgx5", driver)
+}
+
+// withMigrationLock выполняет fn под advisory-блокировкой миграций
+func withMigrationLock(ctx context.Context, pool *pgxpool.Pool, fn func(m *migrate.Migrate) error) error {
+	return database.WithAdvisoryLock(ctx, pool, migrationLockKey, func() error {
+		m, err := newMigrate(pool)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return fn(m)
+	})
+}
+
+func migrateUp(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger) error {
+	err := withMigrationLock(ctx, pool, func(m *migrate.Migrate) error {
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("migration error", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// runMigrate - подкоманда migrate. down без аргумента откатывает одну миграцию
+func runMigrate(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+	case "down":
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				return 2
+			}
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPostgresPool(ctx, cfg.Database, logger)
+	if err != nil {
+		return 1
+	}
+	defer closeDB(pool, logger)
+
+	switch args[0] {
+	case "up":
+		if err := migrateUp(ctx, pool, logger); err != nil {
+			return 1
+		}
+	case "down":
+		err := withMigrationLock(ctx, pool, func(m *migrate.Migrate) error {
+			if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("migration error", slog.Any("error", err))
+			return 1
+		}
+	}
+
+	if err := printMigrationVersion(pool); err != nil {
+		logger.Error("failed to get migration version", slog.Any("error", err))
+		return 1
+	}
+	return 0
+}
+
+func printMigrationVersion(pool *pgxpool.Pool) error {
+	m, err := newMigrate(pool)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d, dirty: %t\n", version, dirty)
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/internal/handlers"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+	"ynastt/avito_test_task_backend_2025/server"
+)
+
+// runServe - подкоманда serve: в зависимости от cfg.Migrate применяет миграции и запускает HTTP-сервер
+func runServe(cfg *config.Config, logger *slog.Logger) int {
+	ctx := context.Background()
+	pool, err := database.NewPostgresPool(ctx, cfg.Database, logger)
+	if err != nil {
+		logger.Error("failed to initialize db", "error", err.Error())
+		return 1
+	}
+	defer closeDB(pool, logger)
+
+	// Миграция
+	switch cfg.Migrate {
+	case config.MigrateOff:
+		logger.Info("migrations skipped", slog.String("migrate", cfg.Migrate))
+	case config.MigrateAuto, config.MigrateOnly:
+		if err := migrateUp(ctx, pool, logger); err != nil {
+			return 1
+		}
+		logger.Info("migrations applied")
+		if cfg.Migrate == config.MigrateOnly {
+			return 0
+		}
+	}
+
+	services, err := newServices(cfg, pool, logger)
+	if err != nil {
+		logger.Error("error creating services", slog.Any("error", err))
+		return 1
+	}
+
+	// исполнители очереди фоновых задач и релей outbox.
+	// При остановке задачи получают jobs.drain_timeout на завершение, релей дописывает текущую пачку
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		services.JobService.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		services.OutboxRelay.Run(workersCtx)
+	}()
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+		logger.Info("Background workers stopped")
+	}()
+
+	handlers := handlers.NewHandler(services, logger, cfg.AdminToken)
+
+	srv := new(server.Server)
+	serverErrors := make(chan error, 1)
+	go func() {
+		if err := srv.Run(cfg.Server, handlers.InitRoutes()); err != nil {
+			serverErrors <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case <-quit:
+		logger.Info("Gracefully Shutting Down")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Error("Error occured on server shutting down", slog.Any("error", err))
+		}
+		// catching ctx.Done(). timeout of cfg.Server.ShutdownTimeout.
+		<-ctx.Done()
+		logger.Info("Shutdown timeout expired", slog.Duration("timeout", cfg.Server.ShutdownTimeout))
+
+		logger.Info("Server stopped gracefully")
+	case err := <-serverErrors:
+		logger.Error("Error occured while running server", slog.Any("error", err))
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"ynastt/avito_test_task_backend_2025/internal/config"
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service"
+)
+
+const snapshotUsage = "usage: snapshot export [-o file] | snapshot import <file>"
+
+// runSnapshot - подкоманды `snapshot export` и `snapshot import`
+func runSnapshot(cfg *config.Config, args []string, logger *slog.Logger) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, snapshotUsage)
+		return 2
+	}
+
+	switch {
+	case args[0] == "export" && (len(args) == 1 || len(args) == 3 && args[1] == "-o"):
+		var out io.Writer = os.Stdout
+		if len(args) == 3 {
+			file, err := os.Create(args[2])
+			if err != nil {
+				logger.Error("failed to create snapshot file", slog.Any("error", err))
+				return 1
+			}
+			defer file.Close()
+			out = file
+		}
+
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			snapshot, err := services.SnapshotService.Export(ctx)
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(out).Encode(snapshot)
+		})
+	case args[0] == "import" && len(args) == 2:
+		file, err := os.Open(args[1])
+		if err != nil {
+			logger.Error("failed to open snapshot file", slog.Any("error", err))
+			return 1
+		}
+		defer file.Close()
+
+		var snapshot domain.Snapshot
+		if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+			logger.Error("failed to parse snapshot file", slog.Any("error", err))
+			return 1
+		}
+
+		return withServices(cfg, logger, func(ctx context.Context, services *service.Services) error {
+			result, err := services.SnapshotService.Import(ctx, &snapshot)
+			if err != nil {
+				return err
+			}
+			return printJSON(map[string]interface{}{"imported": result})
+		})
+	default:
+		fmt.Fprintln(os.Stderr, snapshotUsage)
+		return 2
+	}
+}
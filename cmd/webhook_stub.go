@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/service/webhook"
+)
+
+// допустимое расхождение X-Webhook-Timestamp с текущим временем
+const webhookStubTolerance = 5 * time.Minute
+
+// runWebhookStub - подкоманда webhook-stub: локальный получатель вебхуков для ручной проверки.
+// Печатает каждый запрос с результатом проверки подписи; -fail N отвечает 500 на первые N запросов,
+// чтобы проверить повторы. БД и конфигурация не нужны
+func runWebhookStub(args []string, logger *slog.Logger) int {
+	fs := flag.NewFlagSet("webhook-stub", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:9090", "listen address")
+	secret := fs.String("secret", "", "webhook secret for signature verification")
+	failFirst := fs.Int("fail", 0, "respond 500 to the first N requests")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var received atomic.Int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := received.Add(1)
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		signatureValid := *secret != "" && webhook.Verify(*secret,
+			r.Header.Get(webhook.HeaderTimestamp), r.Header.Get(webhook.HeaderSignature), body,
+			webhookStubTolerance, time.Now())
+
+		status := http.StatusOK
+		switch {
+		case *secret != "" && !signatureValid:
+			status = http.StatusUnauthorized
+		case n <= int64(*failFirst):
+			status = http.StatusInternalServerError
+		}
+
+		var printedBody interface{} = string(body)
+		if json.Valid(body) {
+			printedBody = json.RawMessage(body)
+		}
+
+		_ = printJSON(map[string]interface{}{
+			"request":         n,
+			"event":           r.Header.Get(webhook.HeaderEvent),
+			"delivery":        r.Header.Get(webhook.HeaderDelivery),
+			"signature_valid": signatureValid,
+			"response_status": status,
+			"body":            printedBody,
+		})
+		w.WriteHeader(status)
+	})
+
+	logger.Info("webhook stub listening", slog.String("addr", *addr))
+	srv := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		logger.Error("webhook stub stopped", slog.Any("error", err))
+		return 1
+	}
+	return 0
+}
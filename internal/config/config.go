@@ -0,0 +1,468 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+	"ynastt/avito_test_task_backend_2025/server"
+)
+
+// Config - конфигурация сервиса.
+// Источники в порядке возрастания приоритета: значения по умолчанию, YAML-файл, переменные окружения, флаги
+type Config struct {
+	Server     server.Config   `yaml:"server"`
+	Database   database.Config `yaml:"database"`
+	Log        LogConfig       `yaml:"log"`
+	Reviewers  ReviewersConfig `yaml:"reviewers"`
+	Jobs       JobsConfig      `yaml:"jobs"`
+	Outbox     OutboxConfig    `yaml:"outbox"`
+	Webhooks   WebhooksConfig  `yaml:"webhooks"`
+	VCS        VCSConfig       `yaml:"vcs"`
+	AdminToken string          `yaml:"admin_token"`
+
+	// режим миграций при запуске serve: auto, off или only
+	Migrate string `yaml:"migrate"`
+}
+
+const (
+	// применить миграции и запустить сервер
+	MigrateAuto = "auto"
+	// запустить сервер без миграций, их применяет отдельная задача
+	MigrateOff = "off"
+	// применить миграции и завершиться
+	MigrateOnly = "only"
+)
+
+type LogConfig struct {
+	Level string `yaml:"level"`
+}
+
+// политика назначения ревьюеров
+type ReviewersConfig struct {
+	MaxPerPR int `yaml:"max_per_pr"`
+	// стратегия выбора при создании PR: random или avoid_repeats.
+	// Действует после навыков required_areas и владельцев кода: random выбирает среди
+	// оставшихся равных кандидатов случайно, avoid_repeats сначала берет тех, кто реже ревьюил автора
+	Strategy string `yaml:"strategy"`
+	// за какой период avoid_repeats учитывает, кто ревьюил PR автора
+	PairLookback time.Duration `yaml:"pair_lookback"`
+}
+
+const (
+	StrategyRandom       = "random"
+	StrategyAvoidRepeats = "avoid_repeats"
+)
+
+// очередь фоновых задач
+type JobsConfig struct {
+	// сколько задач выполняется параллельно
+	Workers int `yaml:"workers"`
+	// сколько пользователей одной задачи массовой деактивации обрабатывается параллельно
+	DeactivationWorkers int `yaml:"deactivation_workers"`
+	// как часто свободный исполнитель проверяет очередь
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// попыток на задачу, после них задача уходит в DEAD
+	MaxAttempts int `yaml:"max_attempts"`
+	// задержка перед повторной попыткой, удваивается до max_retry_backoff
+	RetryBackoff    time.Duration `yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+	// аренда задачи: если исполнитель не продлил ее, задачу подхватит другой
+	Lease time.Duration `yaml:"lease"`
+	// сколько при остановке ждать выполняющиеся задачи
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
+
+// публикация доменных событий из outbox
+type OutboxConfig struct {
+	// получатели событий: log, webhook
+	Sinks []string `yaml:"sinks"`
+	// как часто проверять новые события, если очередь пуста
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// сколько событий отправлять за одну транзакцию
+	BatchSize int `yaml:"batch_size"`
+	// таймаут отправки одного события одному получателю
+	PublishTimeout time.Duration `yaml:"publish_timeout"`
+	// задержка перед повторной отправкой, удваивается до max_retry_backoff
+	RetryBackoff    time.Duration `yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+	// сколько хранить опубликованные события
+	Retention time.Duration `yaml:"retention"`
+}
+
+// исходящие вебхуки
+type WebhooksConfig struct {
+	// таймаут одного запроса к подписчику
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// интеграция с внешними системами PR: входящие события и запись назначенных ревьюеров
+type VCSConfig struct {
+	// куда записывать назначенных ревьюеров: off, github или fake
+	Writeback string `yaml:"writeback"`
+	// таймаут запроса к API внешней системы
+	Timeout time.Duration `yaml:"timeout"`
+	GitHub  GitHubConfig  `yaml:"github"`
+	GitLab  GitLabConfig  `yaml:"gitlab"`
+}
+
+const (
+	WritebackOff    = "off"
+	WritebackGitHub = "github"
+	// вызовы API только пишутся в лог, для проверки без доступа к GitHub
+	WritebackFake = "fake"
+)
+
+type GitHubConfig struct {
+	// секрет вебхука репозитория; пустой секрет отключает /webhooks/github
+	WebhookSecret string `yaml:"webhook_secret"`
+	// адрес REST API: https://api.github.com или API GitHub Enterprise
+	APIURL string `yaml:"api_url"`
+	// токен с правом записи в pull requests, нужен для writeback: github
+	APIToken string `yaml:"api_token"`
+}
+
+type GitLabConfig struct {
+	// Secret token вебхука; пустой токен отключает /webhooks/gitlab
+	WebhookToken string `yaml:"webhook_token"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: server.Config{
+			Port:            "8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 5 * time.Second,
+			MaxHeaderBytes:  1 << 20, // 1 MB
+		},
+		Database: database.Config{
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+			ConnMaxIdleTime: time.Minute,
+			ConnectRetries:  5,
+			ConnectBackoff:  time.Second,
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Reviewers: ReviewersConfig{
+			MaxPerPR:     2,
+			Strategy:     StrategyRandom,
+			PairLookback: 30 * 24 * time.Hour,
+		},
+		Jobs: JobsConfig{
+			Workers:             2,
+			DeactivationWorkers: 4,
+			PollInterval:        5 * time.Second,
+			MaxAttempts:         5,
+			RetryBackoff:        5 * time.Second,
+			MaxRetryBackoff:     5 * time.Minute,
+			Lease:               30 * time.Second,
+			DrainTimeout:        30 * time.Second,
+		},
+		Outbox: OutboxConfig{
+			Sinks:           []string{"webhook"},
+			PollInterval:    time.Second,
+			BatchSize:       100,
+			PublishTimeout:  10 * time.Second,
+			RetryBackoff:    5 * time.Second,
+			MaxRetryBackoff: 10 * time.Minute,
+			Retention:       7 * 24 * time.Hour,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout: 10 * time.Second,
+		},
+		VCS: VCSConfig{
+			Writeback: WritebackOff,
+			Timeout:   10 * time.Second,
+			GitHub: GitHubConfig{
+				APIURL: "https://api.github.com",
+			},
+		},
+		Migrate: MigrateAuto,
+	}
+}
+
+// Load собирает конфигурацию. Путь к YAML-файлу берется из флага -config или переменной CONFIG_FILE.
+// args - аргументы командной строки подкоманды; неизвестные флаги считаются ошибкой
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML config file")
+	host := fs.String("host", "", "HTTP server host")
+	port := fs.String("port", "", "HTTP server port")
+	logLevel := fs.String("log-level", "", "log level: debug, info, warn, error")
+	migrateMode := fs.String("migrate", "", "migrations on start: auto, off, only")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	// флаги применяем, только если они заданы явно
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "host":
+			cfg.Server.Host = *host
+		case "port":
+			cfg.Server.Port = *port
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "migrate":
+			cfg.Migrate = *migrateMode
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString(&c.Server.Host, "SERVER_HOST")
+	setString(&c.Server.Port, "SERVER_PORT")
+	setString(&c.Database.URL, "DATABASE_URL")
+	setString(&c.Database.Host, "POSTGRES_HOST")
+	setString(&c.Database.Port, "POSTGRES_PORT")
+	setString(&c.Database.Username, "POSTGRES_USERNAME")
+	setString(&c.Database.Password, "POSTGRES_PASSWORD")
+	setString(&c.Database.DBName, "DB_NAME")
+	setString(&c.Database.SSLMode, "DB_SSL")
+	setString(&c.Log.Level, "LOG_LEVEL")
+	setString(&c.AdminToken, "ADMIN_TOKEN")
+	setString(&c.VCS.GitHub.WebhookSecret, "GITHUB_WEBHOOK_SECRET")
+	setString(&c.VCS.GitLab.WebhookToken, "GITLAB_WEBHOOK_TOKEN")
+	setString(&c.VCS.Writeback, "VCS_WRITEBACK")
+	setString(&c.VCS.GitHub.APIURL, "GITHUB_API_URL")
+	setString(&c.VCS.GitHub.APIToken, "GITHUB_API_TOKEN")
+	setString(&c.Reviewers.Strategy, "REVIEWERS_STRATEGY")
+	setString(&c.Migrate, "MIGRATE_MODE")
+	setList(&c.Outbox.Sinks, "OUTBOX_SINKS")
+
+	return errors.Join(
+		setDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT"),
+		setDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT"),
+		setDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT"),
+		setDuration(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT"),
+		setInt(&c.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		setInt(&c.Database.MinConns, "DB_MIN_CONNS"),
+		setDuration(&c.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		setDuration(&c.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"),
+		setInt(&c.Database.ConnectRetries, "DB_CONNECT_RETRIES"),
+		setDuration(&c.Database.ConnectBackoff, "DB_CONNECT_BACKOFF"),
+		setInt(&c.Reviewers.MaxPerPR, "REVIEWERS_MAX_PER_PR"),
+		setDuration(&c.Reviewers.PairLookback, "REVIEWERS_PAIR_LOOKBACK"),
+		setInt(&c.Jobs.Workers, "JOBS_WORKERS"),
+		setInt(&c.Jobs.DeactivationWorkers, "JOBS_DEACTIVATION_WORKERS"),
+		setDuration(&c.Jobs.PollInterval, "JOBS_POLL_INTERVAL"),
+		setInt(&c.Jobs.MaxAttempts, "JOBS_MAX_ATTEMPTS"),
+		setDuration(&c.Jobs.RetryBackoff, "JOBS_RETRY_BACKOFF"),
+		setDuration(&c.Jobs.MaxRetryBackoff, "JOBS_MAX_RETRY_BACKOFF"),
+		setDuration(&c.Jobs.Lease, "JOBS_LEASE"),
+		setDuration(&c.Jobs.DrainTimeout, "JOBS_DRAIN_TIMEOUT"),
+		setDuration(&c.Outbox.PollInterval, "OUTBOX_POLL_INTERVAL"),
+		setInt(&c.Outbox.BatchSize, "OUTBOX_BATCH_SIZE"),
+		setDuration(&c.Outbox.PublishTimeout, "OUTBOX_PUBLISH_TIMEOUT"),
+		setDuration(&c.Outbox.RetryBackoff, "OUTBOX_RETRY_BACKOFF"),
+		setDuration(&c.Outbox.MaxRetryBackoff, "OUTBOX_MAX_RETRY_BACKOFF"),
+		setDuration(&c.Outbox.Retention, "OUTBOX_RETENTION"),
+		setDuration(&c.Webhooks.Timeout, "WEBHOOKS_TIMEOUT"),
+		setDuration(&c.VCS.Timeout, "VCS_TIMEOUT"),
+	)
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	port, err := strconv.Atoi(c.Server.Port)
+	if err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be a number in 1..65535, got %q", c.Server.Port))
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("server timeouts must be positive"))
+	}
+
+	// отдельные параметры подключения нужны, только если не задан DATABASE_URL
+	if c.Database.URL == "" {
+		if c.Database.Host == "" {
+			errs = append(errs, errors.New("database.host (POSTGRES_HOST) is required"))
+		}
+		if c.Database.Username == "" {
+			errs = append(errs, errors.New("database.username (POSTGRES_USERNAME) is required"))
+		}
+		if c.Database.DBName == "" {
+			errs = append(errs, errors.New("database.db_name (DB_NAME) is required"))
+		}
+	} else if u, err := url.Parse(c.Database.URL); err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		errs = append(errs, errors.New("database.url (DATABASE_URL) must be a postgres:// URL"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MinConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MinConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.min_conns must not exceed database.max_open_conns"))
+	}
+	if c.Database.ConnectRetries < 0 || c.Database.ConnectBackoff <= 0 {
+		errs = append(errs, errors.New("database.connect_retries must not be negative and database.connect_backoff must be positive"))
+	}
+
+	if _, err := c.SlogLevel(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Reviewers.MaxPerPR < 1 {
+		errs = append(errs, errors.New("reviewers.max_per_pr must be at least 1"))
+	}
+	switch c.Reviewers.Strategy {
+	case StrategyRandom:
+	case StrategyAvoidRepeats:
+		if c.Reviewers.PairLookback <= 0 {
+			errs = append(errs, errors.New("reviewers.pair_lookback must be positive for reviewers.strategy: avoid_repeats"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("reviewers.strategy must be one of random, avoid_repeats, got %q", c.Reviewers.Strategy))
+	}
+
+	if c.Jobs.Workers < 1 || c.Jobs.DeactivationWorkers < 1 || c.Jobs.MaxAttempts < 1 {
+		errs = append(errs, errors.New("jobs.workers, jobs.deactivation_workers and jobs.max_attempts must be at least 1"))
+	}
+	if c.Jobs.PollInterval <= 0 || c.Jobs.RetryBackoff <= 0 || c.Jobs.DrainTimeout <= 0 || c.Jobs.MaxRetryBackoff < c.Jobs.RetryBackoff {
+		errs = append(errs, errors.New("jobs intervals must be positive and jobs.max_retry_backoff must not be less than jobs.retry_backoff"))
+	}
+	if c.Jobs.Lease < time.Second {
+		errs = append(errs, errors.New("jobs.lease must be at least 1s"))
+	}
+
+	for _, sink := range c.Outbox.Sinks {
+		if sink != "log" && sink != "webhook" {
+			errs = append(errs, fmt.Errorf("outbox.sinks: unknown sink %q, supported: log, webhook", sink))
+		}
+	}
+	if c.Outbox.BatchSize < 1 {
+		errs = append(errs, errors.New("outbox.batch_size must be at least 1"))
+	}
+	if c.Outbox.PollInterval <= 0 || c.Outbox.PublishTimeout <= 0 || c.Outbox.RetryBackoff <= 0 ||
+		c.Outbox.Retention <= 0 || c.Outbox.MaxRetryBackoff < c.Outbox.RetryBackoff {
+		errs = append(errs, errors.New("outbox intervals must be positive and outbox.max_retry_backoff must not be less than outbox.retry_backoff"))
+	}
+
+	if c.Webhooks.Timeout <= 0 {
+		errs = append(errs, errors.New("webhooks.timeout must be positive"))
+	}
+
+	switch c.VCS.Writeback {
+	case WritebackOff, WritebackFake:
+	case WritebackGitHub:
+		if c.VCS.GitHub.APIToken == "" {
+			errs = append(errs, errors.New("vcs.github.api_token (GITHUB_API_TOKEN) is required for vcs.writeback: github"))
+		}
+		if u, err := url.Parse(c.VCS.GitHub.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("vcs.github.api_url must be an absolute http or https URL"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("vcs.writeback must be one of off, github, fake, got %q", c.VCS.Writeback))
+	}
+	if c.VCS.Timeout <= 0 {
+		errs = append(errs, errors.New("vcs.timeout must be positive"))
+	}
+
+	switch c.Migrate {
+	case MigrateAuto, MigrateOff, MigrateOnly:
+	default:
+		errs = append(errs, fmt.Errorf("migrate must be one of auto, off, only, got %q", c.Migrate))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c *Config) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(c.Log.Level))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("log.level must be one of debug, info, warn, error, got %q", c.Log.Level)
+	}
+	return level, nil
+}
+
+func setString(dst *string, key string) {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		*dst = value
+	}
+}
+
+// setList разбирает список через запятую; пустые элементы пропускаются
+func setList(dst *[]string, key string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*dst = list
+}
+
+func setInt(dst *int, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	*dst = parsed
+	return nil
+}
+
+func setDuration(dst *time.Duration, key string) error {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration like 10s: %w", key, err)
+	}
+	*dst = parsed
+	return nil
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobState - состояние задачи в общей очереди фоновых задач
+type JobState string
+
+const (
+	// ждет исполнителя, в том числе повторной попытки после ошибки
+	JobStatePending   JobState = "PENDING"
+	JobStateRunning   JobState = "RUNNING"
+	JobStateSucceeded JobState = "SUCCEEDED"
+	// попытки исчерпаны, задача ждет ручного retry
+	JobStateDead      JobState = "DEAD"
+	JobStateCancelled JobState = "CANCELLED"
+)
+
+func (s JobState) IsValid() bool {
+	switch s {
+	case JobStatePending, JobStateRunning, JobStateSucceeded, JobStateDead, JobStateCancelled:
+		return true
+	}
+	return false
+}
+
+// Job - задача общей очереди. Kind определяет обработчик, Payload - его параметры
+type Job struct {
+	ID          string          `json:"job_id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      JobState        `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+type JobsFilter struct {
+	Status JobState
+	Kind   string
+	Limit  int
+}
+
+type JobsResponse struct {
+	Jobs []Job `json:"jobs"`
+}
@@ -7,6 +7,7 @@ var (
 	ErrTeamExists   = errors.New("team_name already exists")
 	ErrPRExists     = errors.New("PR id already exists")
 	ErrPRMerged     = errors.New("cannot reassign on merged PR")
+	ErrPRClosed     = errors.New("PR is closed")
 	ErrNotAssigned  = errors.New("reviewer is not assigned to this PR")
 	ErrNoCandidate  = errors.New("no active replacement candidate in team")
 	ErrNotFound     = errors.New("resource not found")
@@ -14,6 +15,32 @@ var (
 	ErrUserNotFound = errors.New("user not found")
 	ErrPRNotFound   = errors.New("PR not found")
 	ErrEmptyUserIDs = errors.New("user_ids cannot be empty")
+	ErrJobNotFound  = errors.New("job not found")
+
+	// отменить можно только ожидающую или выполняющуюся задачу
+	ErrJobNotCancellable = errors.New("job is already finished")
+	// повторить можно только отмененную задачу или задачу с исчерпанными попытками
+	ErrJobNotRetryable = errors.New("only DEAD or CANCELLED jobs can be retried")
+
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrInvalidWebhookURL = errors.New("url must be an absolute http or https URL")
+	ErrInvalidEventTypes = errors.New("event_types must be a non-empty list of pr.created, reviewer.assigned, reviewer.replaced, pr.merged, user.deactivated")
+
+	// ревьюер, запрошенный во внешней системе, не может быть назначен
+	ErrReviewerIsAuthor = errors.New("author cannot review own PR")
+	ErrUserInactive     = errors.New("user is inactive")
+
+	ErrUnknownVCSProvider  = errors.New("provider must be github or gitlab")
+	ErrIdentityNotFound    = errors.New("identity not found")
+	ErrVCSDisabled         = errors.New("integration is disabled: webhook secret is not configured")
+	ErrInvalidVCSSignature = errors.New("invalid webhook signature")
+	ErrInvalidVCSToken     = errors.New("invalid webhook token")
+
+	ErrInvalidCodeOwners  = errors.New("invalid CODEOWNERS")
+	ErrCodeOwnersNotFound = errors.New("team has no CODEOWNERS")
+
+	// версия ресурса не совпала с переданной в If-Match
+	ErrVersionConflict = errors.New("resource has been modified, version does not match If-Match")
 )
 
 type ErrorResponse struct {
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPRCreated          EventType = "pr.created"
+	EventPRMerged           EventType = "pr.merged"
+	EventPRClosed           EventType = "pr.closed"
+	EventPRReopened         EventType = "pr.reopened"
+	EventReviewerReassigned EventType = "pr.reviewer_reassigned"
+	EventReviewerAssigned   EventType = "pr.reviewer_assigned"
+	EventUserDeactivated    EventType = "user.deactivated"
+)
+
+// OutboxEvent - доменное событие. Записывается в той же транзакции, что и изменение,
+// и публикуется релеем не менее одного раза; получатели дедуплицируют события по ID
+type OutboxEvent struct {
+	ID          int64           `json:"id"`
+	Type        EventType       `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Attempts    int             `json:"-"`
+}
+
+// NewEvent собирает событие; aggregateID - id PR или пользователя, к которому относится событие
+func NewEvent(eventType EventType, aggregateID string, payload interface{}) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return OutboxEvent{Type: eventType, AggregateID: aggregateID, Payload: data}, nil
+}
+
+// payload pr.created, pr.merged, pr.closed и pr.reopened
+type PREvent struct {
+	PullRequest *PullRequest `json:"pull_request"`
+}
+
+// payload pr.reviewer_reassigned. Пустой NewReviewerID - ревьюер удален без замены
+type ReviewerReassignedEvent struct {
+	PRID          string         `json:"pull_request_id"`
+	OldReviewerID string         `json:"old_reviewer_id"`
+	NewReviewerID string         `json:"new_reviewer_id,omitempty"`
+	Reason        ReassignReason `json:"reason"`
+}
+
+// payload pr.reviewer_assigned: ревьюер добавлен к уже назначенным
+type ReviewerAssignedEvent struct {
+	PRID       string         `json:"pull_request_id"`
+	ReviewerID string         `json:"reviewer_id"`
+	Reason     ReassignReason `json:"reason"`
+}
+
+// payload user.deactivated
+type UserDeactivatedEvent struct {
+	UserID   string    `json:"user_id"`
+	TeamName string    `json:"team_name"`
+	PRsInfo  []PRsInfo `json:"pull_requests_info"`
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ограничения на теги навыков пользователей и областей PR
+const (
+	MaxTags      = 20
+	maxTagLength = 32
+)
+
+var (
+	ErrInvalidTags = errors.New("tags must be 1-32 characters of letters, digits, '+', '#', '.', '_' or '-', at most 20 per list")
+
+	tagPattern = regexp.MustCompile(`^[a-z0-9+#._-]+$`)
+)
+
+type SetSkillsRequest struct {
+	UserID string   `json:"user_id"`
+	Skills []string `json:"skills"`
+}
+
+// NormalizeTags приводит теги к нижнему регистру и убирает повторы, сохраняя порядок
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if len(tag) > maxTagLength || !tagPattern.MatchString(tag) {
+			return nil, ErrInvalidTags
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, ErrInvalidTags
+	}
+	return normalized, nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+type HealthResponse struct {
+	Status   string         `json:"status"`
+	Database DatabaseHealth `json:"database"`
+}
+
+type DatabaseHealth struct {
+	Status string      `json:"status"`
+	Pool   DBPoolStats `json:"pool"`
+}
+
+// состояние пула соединений pgxpool
+type DBPoolStats struct {
+	MaxConns                int32 `json:"max_conns"`
+	TotalConns              int32 `json:"total_conns"`
+	AcquiredConns           int32 `json:"acquired_conns"`
+	IdleConns               int32 `json:"idle_conns"`
+	AcquireCount            int64 `json:"acquire_count"`
+	AcquireDurationMs       int64 `json:"acquire_duration_ms"`
+	EmptyAcquireCount       int64 `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64 `json:"canceled_acquire_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+}
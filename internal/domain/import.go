@@ -0,0 +1,40 @@
+package domain
+
+import "errors"
+
+var (
+	ErrImportFormat    = errors.New("unsupported import format, expected csv or yaml")
+	ErrImportConflicts = errors.New("import has conflicts, nothing applied")
+)
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatYAML = "yaml"
+)
+
+// строка файла импорта: пользователь и его команда
+type ImportRecord struct {
+	Line     int    `json:"line"`
+	TeamName string `json:"team"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+type ImportReport struct {
+	DryRun       bool     `json:"dry_run"`
+	Applied      bool     `json:"applied"`
+	TeamsCreated []string `json:"teams_created"`
+	UsersCreated []string `json:"users_created"`
+	UsersUpdated []string `json:"users_updated"`
+	// пользователи из UsersUpdated, которые станут неактивными: их открытые PR будут переназначены
+	UsersDeactivated []string         `json:"users_deactivated"`
+	Unchanged        int              `json:"unchanged"`
+	Conflicts        []ImportConflict `json:"conflicts,omitempty"`
+}
+
+type ImportConflict struct {
+	Line   int    `json:"line"`
+	UserID string `json:"user_id,omitempty"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "PENDING"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusCompleted JobStatus = "COMPLETED"
+	JobStatusFailed    JobStatus = "FAILED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+)
+
+// JobStatusFromState - статус задачи массовой деактивации по состоянию ее задачи в общей очереди
+func JobStatusFromState(state JobState) JobStatus {
+	switch state {
+	case JobStateRunning:
+		return JobStatusRunning
+	case JobStateSucceeded:
+		return JobStatusCompleted
+	case JobStateDead:
+		return JobStatusFailed
+	case JobStateCancelled:
+		return JobStatusCancelled
+	}
+	return JobStatusPending
+}
+
+type JobItemStatus string
+
+const (
+	JobItemPending JobItemStatus = "PENDING"
+	JobItemDone    JobItemStatus = "DONE"
+	JobItemFailed  JobItemStatus = "FAILED"
+)
+
+// DeactivationJob - фоновая задача массовой деактивации пользователей
+type DeactivationJob struct {
+	ID         string     `json:"job_id"`
+	Status     JobStatus  `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Failed     int        `json:"failed"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// результат по уже обработанным пользователям
+	Result *BulkDeactivateResponse `json:"result,omitempty"`
+}
+
+// DeactivationJobItem - один пользователь задачи, обрабатываются в порядке Position
+type DeactivationJobItem struct {
+	Position int
+	UserID   string
+	Status   JobItemStatus
+	Error    string
+	PRsInfo  []PRsInfo
+}
@@ -10,29 +10,113 @@ type PRStatus string
 const (
 	PRStatusOpen   PRStatus = "OPEN"
 	PRStatusMerged PRStatus = "MERGED"
+	// PR закрыт без merge во внешней системе и может быть переоткрыт
+	PRStatusClosed PRStatus = "CLOSED"
 )
 
+type ReassignReason string
+
+const (
+	// переназначение через /pullRequest/reassign
+	ReassignReasonManual ReassignReason = "MANUAL"
+
+	// переназначение при деактивации ревьюера
+	ReassignReasonDeactivation ReassignReason = "DEACTIVATION"
+
+	// ревьюер запрошен во внешней системе (review_requested в GitHub)
+	ReassignReasonRequested ReassignReason = "REQUESTED"
+)
+
+// замена ревьюера на PR, при пустом NewReviewerID ревьюер удаляется без замены
+type ReviewerChange struct {
+	PRID          string
+	OldReviewerID string
+	NewReviewerID string
+	Reason        ReassignReason
+}
+
 type PullRequest struct {
-	ID                string     `json:"pull_request_id"`
-	Name              string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            PRStatus   `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	ID                string   `json:"pull_request_id"`
+	Name              string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            PRStatus `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	// области, которые должны покрыть ревьюеры, - навыки пользователей
+	RequiredAreas []string   `json:"required_areas,omitempty"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	MergedAt      *time.Time `json:"mergedAt,omitempty"`
+	Version       int64      `json:"version"`
+	// как выбраны ревьюеры, только в ответе на создание PR
+	Selection *ReviewerSelection `json:"selection,omitempty"`
+}
+
+// стратегия автоматического выбора ревьюеров при создании PR
+type ReviewerStrategy string
+
+const (
+	// случайные участники команды
+	ReviewerStrategyRandom ReviewerStrategy = "random"
+
+	// предпочтение тем, кто реже ревьюил автора за окно reviewers.pair_lookback
+	ReviewerStrategyAvoidRepeats ReviewerStrategy = "avoid_repeats"
+)
+
+type ReviewerSelection struct {
+	Strategy ReviewerStrategy `json:"strategy"`
+	// окно истории PR для avoid_repeats, например "720h0m0s"
+	PairLookback string `json:"pair_lookback,omitempty"`
+	// области из required_areas, для которых среди кандидатов нет ревьюера с навыком
+	UncoveredAreas []string            `json:"uncovered_areas"`
+	Reviewers      []ReviewerRationale `json:"reviewers"`
+}
+
+// почему выбран ревьюер
+type ReviewerRationale struct {
+	UserID string `json:"user_id"`
+	// области PR, которые покрывают навыки ревьюера
+	CoveredAreas []string `json:"covered_areas,omitempty"`
+	// сколько измененных файлов принадлежит ревьюеру по CODEOWNERS
+	OwnedFiles int `json:"owned_files,omitempty"`
+	// сколько PR автора ревьюер получил за окно pair_lookback, только для avoid_repeats
+	RecentReviews *int   `json:"recent_reviews,omitempty"`
+	Reason        string `json:"reason"`
+}
+
+// PR с развернутой информацией о ревьюерах
+type PullRequestDetails struct {
+	ID                string         `json:"pull_request_id"`
+	Name              string         `json:"pull_request_name"`
+	AuthorID          string         `json:"author_id"`
+	Status            PRStatus       `json:"status"`
+	AssignedReviewers []ReviewerInfo `json:"assigned_reviewers"`
+	CreatedAt         *time.Time     `json:"createdAt,omitempty"`
+	MergedAt          *time.Time     `json:"mergedAt,omitempty"`
+	Version           int64          `json:"version"`
+}
+
+type ReviewerInfo struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
 }
 
 type PullRequestShort struct {
-	ID       string   `json:"pull_request_id"`
-	Name     string   `json:"pull_request_name"`
-	AuthorID string   `json:"author_id"`
-	Status   PRStatus `json:"status"`
+	ID        string     `json:"pull_request_id"`
+	Name      string     `json:"pull_request_name"`
+	AuthorID  string     `json:"author_id"`
+	Status    PRStatus   `json:"status"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
 }
 
 type CreatePRRequest struct {
 	ID       string `json:"pull_request_id"`
 	Name     string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
+	// измененные файлы; владельцы по CODEOWNERS команды автора назначаются в первую очередь
+	ChangedFiles []string `json:"changed_files,omitempty"`
+	// области PR; ревьюеры подбираются так, чтобы у каждой был ревьюер с таким навыком
+	RequiredAreas []string `json:"required_areas,omitempty"`
 }
 
 type MergePRRequest struct {
@@ -52,11 +136,20 @@ type ReassignResponse struct {
 type UserReviewsResponse struct {
 	UserID       string             `json:"user_id"`
 	PullRequests []PullRequestShort `json:"pull_requests"`
+	NextCursor   string             `json:"next_cursor,omitempty"`
+}
+
+// фильтр и параметры пагинации для списка PR ревьюера
+type ReviewerPRsFilter struct {
+	Status PRStatus
+	Since  *time.Time
+	Cursor *Cursor
+	Limit  int
 }
 
 func (s PRStatus) IsValid() bool {
 	switch s {
-	case PRStatusOpen, PRStatusMerged:
+	case PRStatusOpen, PRStatusMerged, PRStatusClosed:
 		return true
 	default:
 		return false
@@ -73,3 +166,7 @@ func (pr *PullRequest) ValidateStatus() error {
 func (pr *PullRequest) IsPRMerged() bool {
 	return pr.Status == PRStatusMerged
 }
+
+func (pr *PullRequest) IsPRClosed() bool {
+	return pr.Status == PRStatusClosed
+}
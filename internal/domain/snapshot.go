@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// версия формата снапшота, увеличивается при несовместимых изменениях.
+// Версия 2 добавила CODEOWNERS команд; снапшоты версии 1 восстанавливаются без них
+const (
+	SnapshotVersion    = 2
+	MinSnapshotVersion = 1
+)
+
+var (
+	ErrSnapshotVersion  = errors.New("unsupported snapshot version")
+	ErrDatabaseNotEmpty = errors.New("snapshot can only be restored into an empty database")
+)
+
+// Snapshot - полная выгрузка данных сервиса для резервного копирования и переноса между окружениями
+type Snapshot struct {
+	Version       int                    `json:"version"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	Teams         []SnapshotTeam         `json:"teams"`
+	Users         []SnapshotUser         `json:"users"`
+	PullRequests  []SnapshotPR           `json:"pull_requests"`
+	Reassignments []SnapshotReassignment `json:"reassignments"`
+	CodeOwners    []SnapshotCodeOwners   `json:"codeowners"`
+}
+
+type SnapshotTeam struct {
+	TeamName  string    `json:"team_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// в снапшотах, выгруженных до появления версий, поле отсутствует и при восстановлении считается равным 1
+	Version int64 `json:"version,omitempty"`
+}
+
+type SnapshotUser struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	TeamName  string    `json:"team_name"`
+	IsActive  bool      `json:"is_active"`
+	Skills    []string  `json:"skills,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SnapshotPR struct {
+	ID                string     `json:"pull_request_id"`
+	Name              string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            PRStatus   `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	RequiredAreas     []string   `json:"required_areas,omitempty"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	MergedAt          *time.Time `json:"merged_at,omitempty"`
+	Version           int64      `json:"version,omitempty"`
+}
+
+type SnapshotReassignment struct {
+	PRID          string         `json:"pull_request_id"`
+	OldReviewerID string         `json:"old_reviewer_id"`
+	NewReviewerID *string        `json:"new_reviewer_id,omitempty"`
+	Reason        ReassignReason `json:"reason"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// CODEOWNERS команды в исходном виде
+type SnapshotCodeOwners struct {
+	TeamName  string    `json:"team_name"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SnapshotImportResult struct {
+	Teams         int `json:"teams"`
+	Users         int `json:"users"`
+	PullRequests  int `json:"pull_requests"`
+	Reassignments int `json:"reassignments"`
+	CodeOwners    int `json:"codeowners"`
+}
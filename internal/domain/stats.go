@@ -1,13 +1,17 @@
 package domain
 
+import "time"
+
 type StatsResponse struct {
 	TotalTeams      int64                 `json:"total_teams"`
 	TotalUsers      int64                 `json:"total_users"`
 	TotalPRs        int64                 `json:"total_pull_requests"`
 	OpenPRs         int64                 `json:"open_pull_requests"`
 	MergedPRs       int64                 `json:"merged_pull_requests"`
+	ClosedPRs       int64                 `json:"closed_pull_requests"`
 	ActiveUsers     int64                 `json:"active_users"`
 	InactiveUsers   int64                 `json:"inactive_users"`
+	Window          *WindowStats          `json:"window,omitempty"`
 	UserAssignments []UserAssignmentStats `json:"user_assignments,omitempty"`
 	PRAssignments   []PRAssignmentStats   `json:"pr_assignments,omitempty"`
 }
@@ -27,3 +31,86 @@ type PRAssignmentStats struct {
 	Status    string `json:"status"`
 	Reviewers int    `json:"reviewers_count"`
 }
+
+// фильтр статистики по временному окну [From, To) и команде
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+}
+
+func (f StatsFilter) IsEmpty() bool {
+	return f.From == nil && f.To == nil && f.TeamName == ""
+}
+
+func (f StatsFilter) Validate() error {
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return ErrInvalidInput
+	}
+	return nil
+}
+
+type WindowStats struct {
+	From            *time.Time `json:"from,omitempty"`
+	To              *time.Time `json:"to,omitempty"`
+	TeamName        string     `json:"team_name,omitempty"`
+	PRsCreated      int64      `json:"pull_requests_created"`
+	PRsMerged       int64      `json:"pull_requests_merged"`
+	Reassignments   int64      `json:"reassignments"`
+	ActiveReviewers int64      `json:"active_reviewers"`
+}
+
+// Перцентили длительности в секундах
+type DurationPercentiles struct {
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	P99   float64 `json:"p99_seconds"`
+}
+
+type CycleTimeStats struct {
+	From      *time.Time          `json:"from,omitempty"`
+	To        *time.Time          `json:"to,omitempty"`
+	TeamName  string              `json:"team_name,omitempty"`
+	Teams     []TeamCycleTime     `json:"teams"`
+	Reviewers []ReviewerCycleTime `json:"reviewers"`
+}
+
+type TeamCycleTime struct {
+	TeamName    string              `json:"team_name"`
+	TimeToMerge DurationPercentiles `json:"time_to_merge"`
+}
+
+type ReviewerCycleTime struct {
+	UserID      string              `json:"user_id"`
+	Username    string              `json:"username"`
+	TeamName    string              `json:"team_name"`
+	TimeToMerge DurationPercentiles `json:"time_to_merge"`
+}
+
+// Отчет о равномерности распределения назначений внутри команд
+type FairnessReport struct {
+	From     *time.Time     `json:"from,omitempty"`
+	To       *time.Time     `json:"to,omitempty"`
+	TeamName string         `json:"team_name,omitempty"`
+	Teams    []TeamFairness `json:"teams"`
+}
+
+type TeamFairness struct {
+	TeamName         string           `json:"team_name"`
+	ActiveMembers    int              `json:"active_members"`
+	TotalAssignments int64            `json:"total_assignments"`
+	ExpectedShare    float64          `json:"expected_share"`
+	Gini             float64          `json:"gini"`
+	MostLoaded       []string         `json:"most_loaded"`
+	LeastLoaded      []string         `json:"least_loaded"`
+	Members          []MemberFairness `json:"members"`
+}
+
+type MemberFairness struct {
+	UserID      string  `json:"user_id"`
+	Username    string  `json:"username"`
+	Assignments int64   `json:"assignments"`
+	Share       float64 `json:"share"`
+	Deviation   float64 `json:"deviation"`
+}
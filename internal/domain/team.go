@@ -5,6 +5,8 @@ import "time"
 type Team struct {
 	TeamName string       `json:"team_name"`
 	Members  []TeamMember `json:"members"`
+	// версия команды, меняется при изменении состава или активности участников
+	Version int64 `json:"version,omitempty"`
 }
 
 type TeamMember struct {
@@ -18,3 +20,19 @@ type TeamWithTimestamps struct {
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
 }
+
+// CODEOWNERS команды
+type CodeOwners struct {
+	TeamName  string           `json:"team_name"`
+	Content   string           `json:"content"`
+	Rules     []CodeOwnersRule `json:"rules"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
+	// версия команды: загрузка CODEOWNERS ее увеличивает
+	Version int64 `json:"version,omitempty"`
+}
+
+type CodeOwnersRule struct {
+	Line    int      `json:"line"`
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+}
@@ -13,10 +13,12 @@ const (
 )
 
 type User struct {
-	UserID    string     `json:"user_id"`
-	Username  string     `json:"username"`
-	TeamName  string     `json:"team_name"`
-	IsActive  bool       `json:"is_active"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+	// навыки для выбора ревьюеров по областям PR (go, postgres, frontend)
+	Skills    []string   `json:"skills,omitempty"`
 	CreatedAt *time.Time `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
 }
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+type VCSProvider string
+
+const (
+	VCSGitHub VCSProvider = "github"
+	VCSGitLab VCSProvider = "gitlab"
+)
+
+// VCSIdentity сопоставляет логин во внешней системе пользователю сервиса
+type VCSIdentity struct {
+	Provider  VCSProvider `json:"provider"`
+	Login     string      `json:"login"`
+	UserID    string      `json:"user_id"`
+	CreatedAt *time.Time  `json:"created_at,omitempty"`
+}
+
+type IdentitiesResponse struct {
+	Identities []VCSIdentity `json:"identities"`
+}
+
+// VCSWriteback - решение о ревьюерах PR, которое нужно показать во внешней системе
+type VCSWriteback struct {
+	PRID              string   `json:"pull_request_id"`
+	AddedReviewerIDs  []string `json:"added_reviewer_ids,omitempty"`
+	RemovedReviewerID string   `json:"removed_reviewer_id,omitempty"`
+	// CREATED для нового PR или причина переназначения
+	Reason string `json:"reason"`
+}
+
+type VCSEventOutcome string
+
+const (
+	VCSOutcomeCreated          VCSEventOutcome = "PR_CREATED"
+	VCSOutcomeMerged           VCSEventOutcome = "PR_MERGED"
+	VCSOutcomeClosed           VCSEventOutcome = "PR_CLOSED"
+	VCSOutcomeReopened         VCSEventOutcome = "PR_REOPENED"
+	VCSOutcomeReviewerAssigned VCSEventOutcome = "REVIEWER_ASSIGNED"
+	// событие не меняет данные сервиса, причина - в Reason
+	VCSOutcomeIgnored VCSEventOutcome = "IGNORED"
+)
+
+// VCSEventResult - результат обработки входящего события внешней системы
+type VCSEventResult struct {
+	Provider      VCSProvider     `json:"provider"`
+	Event         string          `json:"event"`
+	Action        string          `json:"action,omitempty"`
+	Outcome       VCSEventOutcome `json:"outcome"`
+	PullRequestID string          `json:"pull_request_id,omitempty"`
+	// ревьюеры, добавленные по запросу ревью во внешней системе
+	AddedReviewers []string `json:"added_reviewers,omitempty"`
+	Reason         string   `json:"reason,omitempty"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "slices"
+
+// CheckVersion сравнивает текущую версию ресурса с ожидаемыми клиентом (из If-Match).
+// nil означает, что клиент версию не передал и проверка не нужна, иначе текущая версия должна совпасть с одной из ожидаемых
+func CheckVersion(expected []int64, actual int64) error {
+	if expected != nil && !slices.Contains(expected, actual) {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventType - событие, на которое можно подписать вебхук
+type WebhookEventType string
+
+const (
+	WebhookPRCreated        WebhookEventType = "pr.created"
+	WebhookReviewerAssigned WebhookEventType = "reviewer.assigned"
+	WebhookReviewerReplaced WebhookEventType = "reviewer.replaced"
+	WebhookPRMerged         WebhookEventType = "pr.merged"
+	WebhookUserDeactivated  WebhookEventType = "user.deactivated"
+	// тестовая доставка, отправляется только вручную
+	WebhookPing WebhookEventType = "ping"
+)
+
+func (t WebhookEventType) IsValid() bool {
+	switch t {
+	case WebhookPRCreated, WebhookReviewerAssigned, WebhookReviewerReplaced, WebhookPRMerged, WebhookUserDeactivated:
+		return true
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// попытки исчерпаны
+	WebhookDeliveryFailed WebhookDeliveryStatus = "FAILED"
+	// подписку удалили или отключили до доставки
+	WebhookDeliveryCancelled WebhookDeliveryStatus = "CANCELLED"
+)
+
+type CreateWebhookRequest struct {
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	// если не задан, генерируется
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookSubscription - подписка на события. Secret возвращается только при создании
+type WebhookSubscription struct {
+	ID         string             `json:"webhook_id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	Secret     string             `json:"secret,omitempty"`
+	IsActive   bool               `json:"is_active"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+// WebhookDelivery - доставка одного события одной подписке со всеми попытками
+type WebhookDelivery struct {
+	ID             int64                 `json:"delivery_id"`
+	SubscriptionID string                `json:"webhook_id"`
+	EventID        *int64                `json:"event_id,omitempty"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	Attempts       []WebhookAttempt      `json:"attempts"`
+}
+
+// WebhookAttempt - одна HTTP-попытка доставки
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookBody - тело запроса к подписчику. DeliveryID не меняется между повторами и годится для дедупликации
+type WebhookBody struct {
+	DeliveryID int64            `json:"delivery_id"`
+	Event      WebhookEventType `json:"event"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       json.RawMessage  `json:"data"`
+}
+
+// data события reviewer.assigned. Reason - CREATED для ревьюеров, назначенных при создании PR
+type ReviewerAssignedWebhook struct {
+	PRID       string `json:"pull_request_id"`
+	ReviewerID string `json:"reviewer_id"`
+	Reason     string `json:"reason"`
+}
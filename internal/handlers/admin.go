@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// максимальный размер загружаемого снапшота
+const maxSnapshotBodyBytes = 100 << 20 // 100 MB
+
+// requireAdmin пропускает запрос только с заголовком Authorization: Bearer <ADMIN_TOKEN>
+func (h *Handler) requireAdmin(c *gin.Context) {
+	if h.adminToken == "" {
+		h.errorResponse(c, http.StatusForbidden, "FORBIDDEN", "admin API is disabled")
+		c.Abort()
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		h.errorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "invalid admin token")
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+func (h *Handler) ExportSnapshot(c *gin.Context) {
+	snapshot, err := h.services.SnapshotService.Export(c.Request.Context())
+	if err != nil {
+		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="snapshot.json"`)
+	h.successResponse(c, http.StatusOK, snapshot)
+}
+
+func (h *Handler) ImportSnapshot(c *gin.Context) {
+	var snapshot domain.Snapshot
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotBodyBytes)
+	if err := json.NewDecoder(body).Decode(&snapshot); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	result, err := h.services.SnapshotService.Import(c.Request.Context(), &snapshot)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSnapshotVersion), errors.Is(err, domain.ErrInvalidCodeOwners):
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		case errors.Is(err, domain.ErrDatabaseNotEmpty):
+			h.errorResponse(c, http.StatusConflict, "DATABASE_NOT_EMPTY", err.Error())
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"imported": result})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// GitHub не применяет CODEOWNERS больше 3 MB
+const maxCodeOwnersBytes = 3 << 20
+
+// SetCodeOwners загружает CODEOWNERS команды; тело запроса - содержимое файла.
+// If-Match относится к версии команды
+func (h *Handler) SetCodeOwners(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "team_name is required")
+		return
+	}
+
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCodeOwnersBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "INVALID_INPUT", "CODEOWNERS is too large")
+		} else {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		}
+		return
+	}
+
+	codeOwners, err := h.services.CodeOwnersService.Set(c.Request.Context(), teamName, string(content), expectedVersion)
+	if err != nil {
+		h.codeOwnersError(c, err)
+		return
+	}
+
+	setETag(c, codeOwners.Version)
+	h.successResponse(c, http.StatusOK, codeOwners)
+}
+
+func (h *Handler) GetCodeOwners(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "team_name is required")
+		return
+	}
+
+	codeOwners, err := h.services.CodeOwnersService.Get(c.Request.Context(), teamName)
+	if err != nil {
+		h.codeOwnersError(c, err)
+		return
+	}
+
+	setETag(c, codeOwners.Version)
+	h.successResponse(c, http.StatusOK, codeOwners)
+}
+
+func (h *Handler) codeOwnersError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCodeOwners):
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	case errors.Is(err, domain.ErrTeamNotFound), errors.Is(err, domain.ErrCodeOwnersNotFound):
+		h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case errors.Is(err, domain.ErrVersionConflict):
+		h.versionConflictResponse(c)
+	default:
+		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// setETag отдает версию ресурса в заголовке ETag в виде строгого тега "<version>"
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// parseIfMatch читает ожидаемые версии из If-Match: один тег или список через запятую (RFC 9110).
+// Без заголовка или с "*" возвращает nil - проверка не нужна.
+// If-Match сравнивается строго, поэтому слабые (W/) и нечисловые теги ни с чем не совпадают и пропускаются.
+// Если в списке не осталось ни одного тега, отправляется ответ 412 и возвращается false
+func (h *Handler) parseIfMatch(c *gin.Context) ([]int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return nil, true
+	}
+
+	var versions []int64
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil {
+			continue
+		}
+		if version, err := strconv.ParseInt(unquoted, 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		h.versionConflictResponse(c)
+		return nil, false
+	}
+	return versions, true
+}
+
+func (h *Handler) versionConflictResponse(c *gin.Context) {
+	h.errorResponse(c, http.StatusPreconditionFailed, "VERSION_CONFLICT", domain.ErrVersionConflict.Error())
+}
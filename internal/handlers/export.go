@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+
+	contentTypeCSV    = "text/csv; charset=utf-8"
+	contentTypeNDJSON = "application/x-ndjson"
+
+	// как часто отправлять накопленные строки клиенту
+	exportFlushEvery = 100
+)
+
+// exportFormat определяет формат ответа: query-параметр format имеет приоритет над заголовком Accept.
+// При неизвестном значении format отправляет ответ 400 и возвращает false
+func (h *Handler) exportFormat(c *gin.Context) (string, bool) {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case "":
+	case formatJSON, formatCSV, formatNDJSON:
+		return format, true
+	default:
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "format must be one of json, csv, ndjson")
+		return "", false
+	}
+
+	for _, part := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/csv":
+			return formatCSV, true
+		case "application/x-ndjson":
+			return formatNDJSON, true
+		}
+	}
+
+	return formatJSON, true
+}
+
+// rowExporter пишет строки в ответ по мере их получения из репозитория
+type rowExporter struct {
+	c       *gin.Context
+	format  string
+	csv     *csv.Writer
+	json    *json.Encoder
+	pending int
+}
+
+// newRowExporter выставляет заголовки ответа и, для CSV, пишет строку с названиями колонок
+func newRowExporter(c *gin.Context, format, filename string, header []string) (*rowExporter, error) {
+	e := &rowExporter{c: c, format: format}
+
+	switch format {
+	case formatCSV:
+		c.Header("Content-Type", contentTypeCSV)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		c.Status(http.StatusOK)
+		e.csv = csv.NewWriter(c.Writer)
+		if err := e.csv.Write(header); err != nil {
+			return nil, err
+		}
+	default:
+		c.Header("Content-Type", contentTypeNDJSON)
+		c.Status(http.StatusOK)
+		e.json = json.NewEncoder(c.Writer)
+	}
+
+	return e, nil
+}
+
+// write пишет запись: для NDJSON сериализуется record, для CSV - row
+func (e *rowExporter) write(record interface{}, row []string) error {
+	var err error
+	if e.format == formatCSV {
+		err = e.csv.Write(row)
+	} else {
+		err = e.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.pending++
+	if e.pending >= exportFlushEvery {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *rowExporter) flush() error {
+	e.pending = 0
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	e.c.Writer.Flush()
+	return nil
+}
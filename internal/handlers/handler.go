@@ -11,14 +11,17 @@ import (
 )
 
 type Handler struct {
-	services *service.Services
-	logger   *slog.Logger
+	services   *service.Services
+	logger     *slog.Logger
+	adminToken string
 }
 
-func NewHandler(services *service.Services, logger *slog.Logger) *Handler {
+// adminToken защищает эндпоинты /admin; пустой токен отключает их
+func NewHandler(services *service.Services, logger *slog.Logger, adminToken string) *Handler {
 	return &Handler{
-		services: services,
-		logger:   logger,
+		services:   services,
+		logger:     logger,
+		adminToken: adminToken,
 	}
 }
 
@@ -27,33 +30,81 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 	config := cors.DefaultConfig() // CORS
 	config.AllowAllOrigins = true  // разрешить все источники
-	config.AllowMethods = []string{"GET", "POST"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type"}
+	config.AllowMethods = []string{"GET", "POST", "DELETE"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "If-Match"}
+	config.ExposeHeaders = []string{"ETag", "Location"}
 
 	router.Use(cors.New(config))
 
+	// проверка доступности и состояние пула соединений с БД
+	router.GET("/health", h.Health)
+
 	team := router.Group("/team")
 	{
 		team.POST("/add", h.CreateTeam)
 		team.GET("/get", h.GetTeam)
+		team.POST("/codeowners", h.SetCodeOwners)
+		team.GET("/codeowners", h.GetCodeOwners)
 	}
 
 	users := router.Group("/users")
 	{
 		users.POST("/setIsActive", h.SetIsActive)
+		users.POST("/setSkills", h.SetSkills)
 		users.GET("/getReview", h.GetReview)
 		users.POST("/deactivate", h.BulkDeactivateUsers) // endpoint для массовой деактивации
 	}
 
+	// прогресс фоновых задач
+	router.GET("/jobs/:id", h.GetJob)
+
 	pullRequest := router.Group("/pullRequest")
 	{
 		pullRequest.POST("/create", h.CreatePullRequest)
 		pullRequest.POST("/merge", h.MergePullRequest)
 		pullRequest.POST("/reassign", h.ReassignPullRequest)
+		pullRequest.GET("/get", h.GetPullRequest)
+	}
+
+	// входящие события внешних систем, защищены подписью
+	router.POST("/webhooks/github", h.GitHubWebhook)
+	router.POST("/webhooks/gitlab", h.GitLabWebhook)
+
+	// endpoint для массового импорта команд и пользователей
+	router.POST("/import", h.ImportTeams)
+
+	// административные эндпоинты
+	admin := router.Group("/admin", h.requireAdmin)
+	{
+		admin.GET("/snapshot", h.ExportSnapshot)
+		admin.POST("/snapshot", h.ImportSnapshot)
+
+		// очередь фоновых задач
+		admin.GET("/jobs", h.ListJobs)
+		admin.GET("/jobs/:id", h.AdminGetJob)
+		admin.POST("/jobs/:id/cancel", h.CancelJob)
+		admin.POST("/jobs/:id/retry", h.RetryJob)
+
+		// подписки на исходящие вебхуки
+		admin.POST("/webhooks", h.CreateWebhook)
+		admin.GET("/webhooks", h.ListWebhooks)
+		admin.GET("/webhooks/:id", h.GetWebhook)
+		admin.DELETE("/webhooks/:id", h.DeleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+		admin.POST("/webhooks/:id/ping", h.PingWebhook)
+
+		// сопоставление логинов GitHub и GitLab пользователям
+		admin.POST("/vcs/identities", h.MapIdentity)
+		admin.GET("/vcs/identities", h.ListIdentities)
+		admin.DELETE("/vcs/identities/:provider/:login", h.DeleteIdentity)
 	}
 
 	//endpoint для статистики
 	router.GET("/stats", h.GetStatistics)
+	router.GET("/stats/cycleTime", h.GetCycleTime)
+	router.GET("/stats/fairness", h.GetFairness)
+	router.GET("/stats/userAssignments", h.GetUserAssignments)
+	router.GET("/stats/prAssignments", h.GetPRAssignments)
 
 	return router
 }
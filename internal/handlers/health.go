@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func (h *Handler) Health(c *gin.Context) {
+	health := h.services.HealthService.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if health.Status != domain.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	h.successResponse(c, status, health)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service/team"
+)
+
+// максимальный размер файла импорта
+const maxImportBodyBytes = 10 << 20 // 10 MB
+
+func (h *Handler) ImportTeams(c *gin.Context) {
+	dryRun := false
+	if dryRunParam := c.Query("dry_run"); dryRunParam != "" {
+		parsed, err := strconv.ParseBool(dryRunParam)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "dry_run must be a boolean")
+			return
+		}
+		dryRun = parsed
+	}
+
+	format := importFormat(c)
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	records, err := team.ParseImport(body, format)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+		return
+	}
+
+	report, err := h.services.TeamService.ImportTeams(c.Request.Context(), records, dryRun)
+	if err != nil {
+		switch err {
+		case domain.ErrImportConflicts:
+			c.JSON(http.StatusConflict, gin.H{
+				"error": domain.ErrorDetail{
+					Code:    "IMPORT_CONFLICTS",
+					Message: err.Error(),
+				},
+				"report": report,
+			})
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"report": report})
+}
+
+// importFormat берет формат из query-параметра format, иначе из Content-Type
+func importFormat(c *gin.Context) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		if format == "yml" {
+			return domain.ImportFormatYAML
+		}
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return domain.ImportFormatCSV
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return domain.ImportFormatYAML
+	default:
+		return ""
+	}
+}
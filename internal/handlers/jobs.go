@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func (h *Handler) GetJob(c *gin.Context) {
+	job, err := h.services.DeactivationJobs.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch err {
+		case domain.ErrJobNotFound:
+			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, job)
+}
+
+func (h *Handler) ListJobs(c *gin.Context) {
+	filter := domain.JobsFilter{
+		Status: domain.JobState(c.Query("status")),
+		Kind:   c.Query("kind"),
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	response, err := h.services.JobService.List(c.Request.Context(), filter)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "status must be one of PENDING, RUNNING, SUCCEEDED, DEAD, CANCELLED")
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, response)
+}
+
+func (h *Handler) AdminGetJob(c *gin.Context) {
+	job, err := h.services.JobService.Get(c.Request.Context(), c.Param("id"))
+	h.jobResponse(c, job, err)
+}
+
+func (h *Handler) CancelJob(c *gin.Context) {
+	job, err := h.services.JobService.Cancel(c.Request.Context(), c.Param("id"))
+	h.jobResponse(c, job, err)
+}
+
+func (h *Handler) RetryJob(c *gin.Context) {
+	job, err := h.services.JobService.Retry(c.Request.Context(), c.Param("id"))
+	h.jobResponse(c, job, err)
+}
+
+func (h *Handler) jobResponse(c *gin.Context, job *domain.Job, err error) {
+	if err != nil {
+		switch err {
+		case domain.ErrJobNotFound:
+			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		case domain.ErrJobNotCancellable, domain.ErrJobNotRetryable:
+			h.errorResponse(c, http.StatusConflict, "JOB_STATE_CONFLICT", err.Error())
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"job": job})
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// parseTimeQuery разбирает query-параметр в формате RFC3339 или YYYY-MM-DD.
+// Для отсутствующего параметра возвращает nil без ошибки
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	return domain.ParseTime(c.Query(name))
+}
@@ -22,12 +22,15 @@ func (h *Handler) CreatePullRequest(c *gin.Context) {
 			h.errorResponse(c, http.StatusConflict, "PR_EXISTS", err.Error())
 		case domain.ErrUserNotFound:
 			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		case domain.ErrInvalidTags:
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "required_areas: "+err.Error())
 		default:
 			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 		}
 		return
 	}
 
+	setETag(c, pr.Version)
 	h.successResponse(c, http.StatusCreated, gin.H{"pr": pr})
 }
 
@@ -38,17 +41,27 @@ func (h *Handler) MergePullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, err := h.services.PullRequestService.MergePullRequest(c.Request.Context(), req.ID)
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	pr, err := h.services.PullRequestService.MergePullRequest(c.Request.Context(), req.ID, expectedVersion)
 	if err != nil {
 		switch err {
 		case domain.ErrPRNotFound:
 			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		case domain.ErrVersionConflict:
+			h.versionConflictResponse(c)
+		case domain.ErrPRClosed:
+			h.errorResponse(c, http.StatusConflict, "PR_CLOSED", err.Error())
 		default:
 			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 		}
 		return
 	}
 
+	setETag(c, pr.Version)
 	h.successResponse(c, http.StatusOK, gin.H{"pr": pr})
 }
 
@@ -59,13 +72,22 @@ func (h *Handler) ReassignPullRequest(c *gin.Context) {
 		return
 	}
 
-	pr, replacedByID, err := h.services.PullRequestService.ReassignReviewer(c.Request.Context(), req.ID, req.OldReviewerID)
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	pr, replacedByID, err := h.services.PullRequestService.ReassignReviewer(c.Request.Context(), req.ID, req.OldReviewerID, expectedVersion)
 	if err != nil {
 		switch err {
+		case domain.ErrVersionConflict:
+			h.versionConflictResponse(c)
 		case domain.ErrPRNotFound, domain.ErrUserNotFound:
 			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
 		case domain.ErrPRMerged:
 			h.errorResponse(c, http.StatusConflict, "PR_MERGED", err.Error())
+		case domain.ErrPRClosed:
+			h.errorResponse(c, http.StatusConflict, "PR_CLOSED", err.Error())
 		case domain.ErrNotAssigned:
 			h.errorResponse(c, http.StatusConflict, "NOT_ASSIGNED", err.Error())
 		case domain.ErrNoCandidate:
@@ -81,5 +103,28 @@ func (h *Handler) ReassignPullRequest(c *gin.Context) {
 		ReplacedBy: replacedByID,
 	}
 
+	setETag(c, pr.Version)
 	h.successResponse(c, http.StatusOK, response)
 }
+
+func (h *Handler) GetPullRequest(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "pull_request_id is required")
+		return
+	}
+
+	pr, err := h.services.PullRequestService.GetPullRequest(c.Request.Context(), prID)
+	if err != nil {
+		switch err {
+		case domain.ErrPRNotFound:
+			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	setETag(c, pr.Version)
+	h.successResponse(c, http.StatusOK, gin.H{"pr": pr})
+}
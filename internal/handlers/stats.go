@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
 )
 
 func (h *Handler) GetStatistics(c *gin.Context) {
@@ -16,9 +19,23 @@ func (h *Handler) GetStatistics(c *gin.Context) {
 		}
 	}
 
-	stats, err := h.services.StatsService.GetStats(c.Request.Context(), includeDetails)
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != formatJSON {
+		h.exportStatistics(c, format, filter, includeDetails)
+		return
+	}
+
+	stats, err := h.services.StatsService.GetStats(c.Request.Context(), filter, includeDetails)
 	if err != nil {
-		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		h.statsErrorResponse(c, err)
 		return
 	}
 
@@ -26,3 +43,273 @@ func (h *Handler) GetStatistics(c *gin.Context) {
 		"stats": stats,
 	})
 }
+
+func (h *Handler) GetCycleTime(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.services.StatsService.GetCycleTime(c.Request.Context(), filter)
+	if err != nil {
+		h.statsErrorResponse(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{
+		"cycle_time": stats,
+	})
+}
+
+func (h *Handler) GetFairness(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.services.StatsService.GetFairness(c.Request.Context(), filter)
+	if err != nil {
+		h.statsErrorResponse(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{
+		"fairness": report,
+	})
+}
+
+// GetUserAssignments - назначения по пользователям, то же, что user_assignments в /stats?details=true
+func (h *Handler) GetUserAssignments(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format == formatJSON {
+		stats := []domain.UserAssignmentStats{}
+		err := h.services.StatsService.StreamUserAssignments(c.Request.Context(), filter, func(s domain.UserAssignmentStats) error {
+			stats = append(stats, s)
+			return nil
+		})
+		if err != nil {
+			h.statsErrorResponse(c, err)
+			return
+		}
+		h.successResponse(c, http.StatusOK, gin.H{"user_assignments": stats})
+		return
+	}
+
+	// окно проверяем до отправки заголовков потокового ответа
+	if err := filter.Validate(); err != nil {
+		h.statsErrorResponse(c, err)
+		return
+	}
+
+	exporter, err := newRowExporter(c, format, "user_assignments", userAssignmentHeader)
+	if err != nil {
+		h.logger.Error("failed to start export", slog.Any("error", err))
+		return
+	}
+	err = h.services.StatsService.StreamUserAssignments(c.Request.Context(), filter, func(s domain.UserAssignmentStats) error {
+		return exporter.write(s, userAssignmentRow(s))
+	})
+	h.finishExport(exporter, err)
+}
+
+// GetPRAssignments - число ревьюеров по PR, то же, что pr_assignments в /stats?details=true
+func (h *Handler) GetPRAssignments(c *gin.Context) {
+	filter, ok := h.parseStatsFilter(c)
+	if !ok {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	if format == formatJSON {
+		stats := []domain.PRAssignmentStats{}
+		err := h.services.StatsService.StreamPRAssignments(c.Request.Context(), filter, func(s domain.PRAssignmentStats) error {
+			stats = append(stats, s)
+			return nil
+		})
+		if err != nil {
+			h.statsErrorResponse(c, err)
+			return
+		}
+		h.successResponse(c, http.StatusOK, gin.H{"pr_assignments": stats})
+		return
+	}
+
+	// окно проверяем до отправки заголовков потокового ответа
+	if err := filter.Validate(); err != nil {
+		h.statsErrorResponse(c, err)
+		return
+	}
+
+	exporter, err := newRowExporter(c, format, "pr_assignments", prAssignmentHeader)
+	if err != nil {
+		h.logger.Error("failed to start export", slog.Any("error", err))
+		return
+	}
+	err = h.services.StatsService.StreamPRAssignments(c.Request.Context(), filter, func(s domain.PRAssignmentStats) error {
+		return exporter.write(s, prAssignmentRow(s))
+	})
+	h.finishExport(exporter, err)
+}
+
+// exportStatistics отдает /stats построчно.
+// CSV содержит пары metric,value; при details=true - одну таблицу с колонкой type, где у каждой строки
+// заполнены только колонки ее типа. NDJSON - запись totals и, при details=true, записи назначений с полем type
+func (h *Handler) exportStatistics(c *gin.Context, format string, filter domain.StatsFilter, includeDetails bool) {
+	ctx := c.Request.Context()
+	stats, err := h.services.StatsService.GetStats(ctx, filter, false)
+	if err != nil {
+		h.statsErrorResponse(c, err)
+		return
+	}
+
+	header := []string{"metric", "value"}
+	if format == formatCSV && includeDetails {
+		header = detailedStatsHeader
+	}
+	exporter, err := newRowExporter(c, format, "stats", header)
+	if err != nil {
+		h.logger.Error("failed to start export", slog.Any("error", err))
+		return
+	}
+
+	if format == formatCSV {
+		for _, row := range statsRows(stats) {
+			if includeDetails {
+				row = detailedStatsRow("totals", 0, row)
+			}
+			if err := exporter.write(nil, row); err != nil {
+				h.finishExport(exporter, err)
+				return
+			}
+		}
+	} else if err := exporter.write(typedRecord{Type: "totals", Record: stats}, nil); err != nil {
+		h.finishExport(exporter, err)
+		return
+	}
+
+	if includeDetails {
+		err = h.services.StatsService.StreamUserAssignments(ctx, filter, func(s domain.UserAssignmentStats) error {
+			return exporter.write(typedRecord{Type: "user_assignment", Record: s},
+				detailedStatsRow("user_assignment", userAssignmentOffset, userAssignmentRow(s)))
+		})
+		if err == nil {
+			err = h.services.StatsService.StreamPRAssignments(ctx, filter, func(s domain.PRAssignmentStats) error {
+				return exporter.write(typedRecord{Type: "pr_assignment", Record: s},
+					detailedStatsRow("pr_assignment", prAssignmentOffset, prAssignmentRow(s)))
+			})
+		}
+	}
+	h.finishExport(exporter, err)
+}
+
+// finishExport дописывает буфер. Статус 200 уже отправлен, поэтому ошибку остается только залогировать
+func (h *Handler) finishExport(exporter *rowExporter, err error) {
+	if err == nil {
+		err = exporter.flush()
+	}
+	if err != nil {
+		h.logger.Error("export interrupted", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) statsErrorResponse(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidInput:
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "from must be before to")
+	default:
+		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+	}
+}
+
+// typedRecord - строка NDJSON с указанием типа записи
+type typedRecord struct {
+	Type   string      `json:"type"`
+	Record interface{} `json:"record"`
+}
+
+var userAssignmentHeader = []string{"user_id", "username", "team_name", "pr_count", "is_active"}
+
+func userAssignmentRow(s domain.UserAssignmentStats) []string {
+	return []string{s.UserID, s.Username, s.TeamName, strconv.FormatInt(s.PRCount, 10), strconv.FormatBool(s.IsActive)}
+}
+
+var prAssignmentHeader = []string{"pull_request_id", "pull_request_name", "author_id", "status", "reviewers_count"}
+
+func prAssignmentRow(s domain.PRAssignmentStats) []string {
+	return []string{s.PRID, s.PRName, s.AuthorID, s.Status, strconv.Itoa(s.Reviewers)}
+}
+
+// detailedStatsHeader - колонки CSV /stats?details=true: type, пары metric,value,
+// затем колонки назначений по пользователям и по PR
+var detailedStatsHeader = append(append([]string{"type", "metric", "value"}, userAssignmentHeader...), prAssignmentHeader...)
+
+// смещения колонок типа записи в detailedStatsHeader без учета колонки type
+var (
+	userAssignmentOffset = 2
+	prAssignmentOffset   = userAssignmentOffset + len(userAssignmentHeader)
+)
+
+// detailedStatsRow раскладывает cells строки типа kind по ее колонкам в detailedStatsHeader
+func detailedStatsRow(kind string, offset int, cells []string) []string {
+	row := make([]string, len(detailedStatsHeader))
+	row[0] = kind
+	copy(row[1+offset:], cells)
+	return row
+}
+
+func statsRows(stats *domain.StatsResponse) [][]string {
+	rows := [][]string{
+		{"total_teams", strconv.FormatInt(stats.TotalTeams, 10)},
+		{"total_users", strconv.FormatInt(stats.TotalUsers, 10)},
+		{"total_pull_requests", strconv.FormatInt(stats.TotalPRs, 10)},
+		{"open_pull_requests", strconv.FormatInt(stats.OpenPRs, 10)},
+		{"merged_pull_requests", strconv.FormatInt(stats.MergedPRs, 10)},
+		{"closed_pull_requests", strconv.FormatInt(stats.ClosedPRs, 10)},
+		{"active_users", strconv.FormatInt(stats.ActiveUsers, 10)},
+		{"inactive_users", strconv.FormatInt(stats.InactiveUsers, 10)},
+	}
+
+	if w := stats.Window; w != nil {
+		rows = append(rows,
+			[]string{"window_pull_requests_created", strconv.FormatInt(w.PRsCreated, 10)},
+			[]string{"window_pull_requests_merged", strconv.FormatInt(w.PRsMerged, 10)},
+			[]string{"window_reassignments", strconv.FormatInt(w.Reassignments, 10)},
+			[]string{"window_active_reviewers", strconv.FormatInt(w.ActiveReviewers, 10)},
+		)
+	}
+
+	return rows
+}
+
+// parseStatsFilter читает параметры временного окна и команды.
+// При ошибке отправляет ответ 400 и возвращает false
+func (h *Handler) parseStatsFilter(c *gin.Context) (domain.StatsFilter, bool) {
+	filter := domain.StatsFilter{
+		TeamName: c.Query("team_name"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "from must be in RFC3339 or YYYY-MM-DD format")
+		return filter, false
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "to must be in RFC3339 or YYYY-MM-DD format")
+		return filter, false
+	}
+
+	return filter, true
+}
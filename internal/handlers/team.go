@@ -15,7 +15,7 @@ func (h *Handler) CreateTeam(c *gin.Context) {
 		return
 	}
 
-	_, err := h.services.TeamService.CreateTeam(c.Request.Context(), req)
+	team, err := h.services.TeamService.CreateTeam(c.Request.Context(), req)
 	if err != nil {
 		switch err {
 		case domain.ErrTeamExists:
@@ -26,7 +26,8 @@ func (h *Handler) CreateTeam(c *gin.Context) {
 		return
 	}
 
-	h.successResponse(c, http.StatusCreated, gin.H{"team": req})
+	setETag(c, team.Version)
+	h.successResponse(c, http.StatusCreated, gin.H{"team": team})
 }
 
 func (h *Handler) GetTeam(c *gin.Context) {
@@ -47,5 +48,6 @@ func (h *Handler) GetTeam(c *gin.Context) {
 		return
 	}
 
+	setETag(c, team.Version)
 	h.successResponse(c, http.StatusOK, team)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"ynastt/avito_test_task_backend_2025/internal/domain"
 
@@ -15,9 +16,41 @@ func (h *Handler) SetIsActive(c *gin.Context) {
 		return
 	}
 
-	user, err := h.services.UserService.SetIsActive(c.Request.Context(), req.UserID, req.IsActive)
+	// If-Match относится к версии команды пользователя
+	expectedVersion, ok := h.parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.services.UserService.SetIsActive(c.Request.Context(), req.UserID, req.IsActive, expectedVersion)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+		case domain.ErrVersionConflict:
+			h.versionConflictResponse(c)
+		default:
+			h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"user": user})
+}
+
+// SetSkills заменяет навыки пользователя, по которым подбираются ревьюеры для областей PR
+func (h *Handler) SetSkills(c *gin.Context) {
+	var req domain.SetSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	user, err := h.services.UserService.SetSkills(c.Request.Context(), req.UserID, req.Skills)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidTags:
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "skills: "+err.Error())
 		case domain.ErrUserNotFound:
 			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
 		default:
@@ -36,9 +69,40 @@ func (h *Handler) GetReview(c *gin.Context) {
 		return
 	}
 
-	response, err := h.services.UserService.GetUserReviewerPRs(c.Request.Context(), userID)
+	filter := domain.ReviewerPRsFilter{
+		Status: domain.PRStatus(c.Query("status")),
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	since, err := parseTimeQuery(c, "since")
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "since must be in RFC3339 or YYYY-MM-DD format")
+		return
+	}
+	filter.Since = since
+
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		cursor, err := domain.DecodeCursor(cursorParam)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+			return
+		}
+		filter.Cursor = cursor
+	}
+
+	response, err := h.services.UserService.GetUserReviewerPRs(c.Request.Context(), userID, filter)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidInput:
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "status must be OPEN, MERGED or CLOSED")
 		case domain.ErrUserNotFound:
 			h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
 		default:
@@ -57,7 +121,8 @@ func (h *Handler) BulkDeactivateUsers(c *gin.Context) {
 		return
 	}
 
-	response, err := h.services.UserService.BulkDeactivateUsers(c.Request.Context(), req.UserIDs)
+	// деактивация выполняется в фоне, прогресс - GET /jobs/{job_id}
+	job, err := h.services.DeactivationJobs.BulkDeactivateUsers(c.Request.Context(), req.UserIDs)
 	if err != nil {
 		switch err {
 		case domain.ErrEmptyUserIDs:
@@ -68,5 +133,6 @@ func (h *Handler) BulkDeactivateUsers(c *gin.Context) {
 		return
 	}
 
-	h.successResponse(c, http.StatusOK, response)
+	c.Header("Location", "/jobs/"+job.ID)
+	h.successResponse(c, http.StatusAccepted, job)
 }
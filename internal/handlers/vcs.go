@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service/vcs"
+)
+
+// максимальный размер события внешней системы; GitHub ограничивает payload 25 MB, GitLab - 25 MB по умолчанию
+const maxVCSEventBodyBytes = 25 << 20
+
+// GitHubWebhook принимает события вебхука репозитория GitHub
+func (h *Handler) GitHubWebhook(c *gin.Context) {
+	body, ok := h.readVCSEventBody(c)
+	if !ok {
+		return
+	}
+
+	svc := h.services.GitHubService
+	if err := svc.VerifySignature(body, c.GetHeader(vcs.GitHubHeaderSignature)); err != nil {
+		h.vcsError(c, err)
+		return
+	}
+
+	result, err := svc.HandleEvent(c.Request.Context(), c.GetHeader(vcs.GitHubHeaderEvent), body)
+	if err != nil {
+		h.logger.Error("failed to handle github event",
+			slog.String("delivery", c.GetHeader(vcs.GitHubHeaderDelivery)),
+			slog.Any("error", err))
+		h.vcsError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, result)
+}
+
+// GitLabWebhook принимает события вебхука проекта или группы GitLab
+func (h *Handler) GitLabWebhook(c *gin.Context) {
+	svc := h.services.GitLabService
+	if err := svc.VerifyToken(c.GetHeader(vcs.GitLabHeaderToken)); err != nil {
+		h.vcsError(c, err)
+		return
+	}
+
+	body, ok := h.readVCSEventBody(c)
+	if !ok {
+		return
+	}
+
+	result, err := svc.HandleEvent(c.Request.Context(), c.GetHeader(vcs.GitLabHeaderEvent), body)
+	if err != nil {
+		h.logger.Error("failed to handle gitlab event",
+			slog.String("event_uuid", c.GetHeader(vcs.GitLabHeaderUUID)),
+			slog.Any("error", err))
+		h.vcsError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, result)
+}
+
+func (h *Handler) readVCSEventBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxVCSEventBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.errorResponse(c, http.StatusRequestEntityTooLarge, "INVALID_INPUT", "request body is too large")
+		} else {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		}
+		return nil, false
+	}
+	return body, true
+}
+
+func (h *Handler) MapIdentity(c *gin.Context) {
+	var req domain.VCSIdentity
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	identity, err := h.services.IdentityService.Map(c.Request.Context(), req)
+	if err != nil {
+		h.vcsError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"identity": identity})
+}
+
+func (h *Handler) ListIdentities(c *gin.Context) {
+	response, err := h.services.IdentityService.List(c.Request.Context(), domain.VCSProvider(c.Query("provider")))
+	if err != nil {
+		h.vcsError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteIdentity(c *gin.Context) {
+	err := h.services.IdentityService.Delete(c.Request.Context(), domain.VCSProvider(c.Param("provider")), c.Param("login"))
+	if err != nil {
+		h.vcsError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) vcsError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrVCSDisabled:
+		h.errorResponse(c, http.StatusForbidden, "FORBIDDEN", err.Error())
+	case domain.ErrInvalidVCSSignature:
+		h.errorResponse(c, http.StatusUnauthorized, "INVALID_SIGNATURE", err.Error())
+	case domain.ErrInvalidVCSToken:
+		h.errorResponse(c, http.StatusUnauthorized, "INVALID_TOKEN", err.Error())
+	case domain.ErrIdentityNotFound, domain.ErrUserNotFound:
+		h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case domain.ErrInvalidInput, domain.ErrUnknownVCSProvider:
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	default:
+		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "invalid request body")
+		return
+	}
+
+	sub, err := h.services.WebhookService.Create(c.Request.Context(), req)
+	if err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusCreated, gin.H{"webhook": sub})
+}
+
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	response, err := h.services.WebhookService.List(c.Request.Context())
+	if err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, response)
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	sub, err := h.services.WebhookService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, gin.H{"webhook": sub})
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	if err := h.services.WebhookService.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	var limit int
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed <= 0 {
+			h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	response, err := h.services.WebhookService.Deliveries(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusOK, response)
+}
+
+func (h *Handler) PingWebhook(c *gin.Context) {
+	delivery, err := h.services.WebhookService.Ping(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.webhookError(c, err)
+		return
+	}
+
+	h.successResponse(c, http.StatusAccepted, gin.H{"delivery": delivery})
+}
+
+func (h *Handler) webhookError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrWebhookNotFound:
+		h.errorResponse(c, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case domain.ErrInvalidWebhookURL, domain.ErrInvalidEventTypes:
+		h.errorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+	default:
+		h.errorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// CodeOwnersRepository хранит CODEOWNERS команд в исходном виде; разбирается файл при чтении
+type CodeOwnersRepository struct {
+	db *database.DB
+}
+
+func NewCodeOwnersRepository(db *database.DB) *CodeOwnersRepository {
+	return &CodeOwnersRepository{db: db}
+}
+
+// Set сохраняет CODEOWNERS команды, заменяя прежний
+func (r *CodeOwnersRepository) Set(ctx context.Context, teamName, content string) (time.Time, error) {
+	conn := r.db.Conn(ctx)
+
+	var updatedAt time.Time
+	err := conn.QueryRow(ctx, `
+		INSERT INTO team_codeowners (team_name, content)
+		VALUES ($1, $2)
+		ON CONFLICT (team_name) DO UPDATE
+		SET content = EXCLUDED.content, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, teamName, content).Scan(&updatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to save CODEOWNERS: %w", err)
+	}
+	return updatedAt, nil
+}
+
+// Get возвращает CODEOWNERS команды; ErrNotFound - команда его не загружала
+func (r *CodeOwnersRepository) Get(ctx context.Context, teamName string) (string, time.Time, error) {
+	conn := r.db.Conn(ctx)
+
+	var content string
+	var updatedAt time.Time
+	err := conn.QueryRow(ctx, `
+		SELECT content, updated_at FROM team_codeowners WHERE team_name = $1
+	`, teamName).Scan(&content, &updatedAt)
+	if err != nil {
+		return "", time.Time{}, HandleNoRowsError(err)
+	}
+	return content, updatedAt, nil
+}
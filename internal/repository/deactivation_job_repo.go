@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+type DeactivationJobRepository struct {
+	db *database.DB
+}
+
+func NewDeactivationJobRepository(db *database.DB) *DeactivationJobRepository {
+	return &DeactivationJobRepository{db: db}
+}
+
+// CreateJob создает задачу с id задачи общей очереди и пользователями в переданном порядке
+func (r *DeactivationJobRepository) CreateJob(ctx context.Context, jobID string, userIDs []string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `INSERT INTO deactivation_jobs (id) VALUES ($1)`, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+
+	_, err = conn.Exec(ctx, `
+		INSERT INTO deactivation_job_items (job_id, position, user_id)
+		SELECT $1, u.position, u.user_id
+		FROM unnest($2::text[]) WITH ORDINALITY AS u(user_id, position)
+	`, jobID, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to insert job items: %w", err)
+	}
+
+	return nil
+}
+
+// GetJob возвращает задачу; статус и время выполнения берутся из задачи общей очереди с тем же id,
+// поэтому отмена и retry через /admin/jobs сразу видны и здесь
+func (r *DeactivationJobRepository) GetJob(ctx context.Context, jobID string) (*domain.DeactivationJob, error) {
+	conn := r.db.Conn(ctx)
+
+	var job domain.DeactivationJob
+	var state string
+	err := conn.QueryRow(ctx, `
+		SELECT d.id, j.status, d.created_at, j.started_at, j.finished_at
+		FROM deactivation_jobs d
+		JOIN jobs j ON j.id = d.id
+		WHERE d.id = $1
+	`, jobID).Scan(&job.ID, &state, &job.CreatedAt, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, HandleNoRowsError(err)
+	}
+
+	job.Status = domain.JobStatusFromState(domain.JobState(state))
+	return &job, nil
+}
+
+// GetItems возвращает пользователей задачи в порядке обработки
+func (r *DeactivationJobRepository) GetItems(ctx context.Context, jobID string) ([]domain.DeactivationJobItem, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT position, user_id, status, COALESCE(error, ''), pull_requests_info
+		FROM deactivation_job_items
+		WHERE job_id = $1
+		ORDER BY position
+	`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []domain.DeactivationJobItem
+	for rows.Next() {
+		var item domain.DeactivationJobItem
+		var status string
+		if err := rows.Scan(&item.Position, &item.UserID, &status, &item.Error, &item.PRsInfo); err != nil {
+			return nil, fmt.Errorf("failed to scan job item: %w", err)
+		}
+		item.Status = domain.JobItemStatus(status)
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// CompleteItem сохраняет результат обработки пользователя задачи
+func (r *DeactivationJobRepository) CompleteItem(ctx context.Context, jobID string, item domain.DeactivationJobItem) error {
+	conn := r.db.Conn(ctx)
+
+	var errMsg *string
+	if item.Error != "" {
+		errMsg = &item.Error
+	}
+
+	_, err := conn.Exec(ctx, `
+		UPDATE deactivation_job_items
+		SET status = $1, error = $2, pull_requests_info = $3, processed_at = CURRENT_TIMESTAMP
+		WHERE job_id = $4 AND position = $5
+	`, item.Status, errMsg, item.PRsInfo, jobID, item.Position)
+	if err != nil {
+		return fmt.Errorf("failed to update job item: %w", err)
+	}
+	return nil
+}
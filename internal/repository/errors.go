@@ -1,17 +1,33 @@
 package repository
 
 import (
-	"database/sql"
 	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
-	ErrNotFound = errors.New("not found")
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
 )
 
+// код ошибки Postgres unique_violation
+const uniqueViolationCode = "23505"
+
 func HandleNoRowsError(err error) error {
-	if errors.Is(err, sql.ErrNoRows) {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return ErrNotFound
 	}
 	return err
 }
+
+// HandleUniqueViolation превращает нарушение уникальности в ErrAlreadyExists.
+// Нужна, когда запись с тем же ключом вставила параллельная транзакция уже после проверки существования
+func HandleUniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// IdentityRepository хранит сопоставление логинов внешних систем пользователям сервиса
+type IdentityRepository struct {
+	db *database.DB
+}
+
+func NewIdentityRepository(db *database.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// Upsert сопоставляет логин пользователю, заменяя прежнее сопоставление того же логина
+func (r *IdentityRepository) Upsert(ctx context.Context, identity domain.VCSIdentity) (*domain.VCSIdentity, error) {
+	conn := r.db.Conn(ctx)
+
+	var saved domain.VCSIdentity
+	var provider string
+	err := conn.QueryRow(ctx, `
+		INSERT INTO vcs_identities (provider, login, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, login) DO UPDATE
+		SET user_id = EXCLUDED.user_id, created_at = CURRENT_TIMESTAMP
+		RETURNING provider, login, user_id, created_at
+	`, string(identity.Provider), identity.Login, identity.UserID).Scan(&provider, &saved.Login, &saved.UserID, &saved.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert identity: %w", err)
+	}
+	saved.Provider = domain.VCSProvider(provider)
+	return &saved, nil
+}
+
+// Resolve возвращает user_id для логина
+func (r *IdentityRepository) Resolve(ctx context.Context, provider domain.VCSProvider, login string) (string, error) {
+	conn := r.db.Conn(ctx)
+
+	var userID string
+	err := conn.QueryRow(ctx, `
+		SELECT user_id FROM vcs_identities WHERE provider = $1 AND login = $2
+	`, string(provider), login).Scan(&userID)
+	if err != nil {
+		return "", HandleNoRowsError(err)
+	}
+	return userID, nil
+}
+
+// LoginsByUserIDs возвращает логины пользователей в системе provider: user_id -> login.
+// Если у пользователя несколько логинов, берется первый по алфавиту; пользователей без логина в ответе нет
+func (r *IdentityRepository) LoginsByUserIDs(ctx context.Context, provider domain.VCSProvider, userIDs []string) (map[string]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT DISTINCT ON (user_id) user_id, login
+		FROM vcs_identities
+		WHERE provider = $1 AND user_id = ANY($2)
+		ORDER BY user_id, login
+	`, string(provider), userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query logins: %w", err)
+	}
+	defer rows.Close()
+
+	logins := make(map[string]string, len(userIDs))
+	for rows.Next() {
+		var userID, login string
+		if err := rows.Scan(&userID, &login); err != nil {
+			return nil, fmt.Errorf("failed to scan login: %w", err)
+		}
+		logins[userID] = login
+	}
+
+	return logins, rows.Err()
+}
+
+// List возвращает сопоставления; пустой provider - всех систем
+func (r *IdentityRepository) List(ctx context.Context, provider domain.VCSProvider) ([]domain.VCSIdentity, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT provider, login, user_id, created_at
+		FROM vcs_identities
+		WHERE $1 = '' OR provider = $1
+		ORDER BY provider, login
+	`, string(provider))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []domain.VCSIdentity{}
+	for rows.Next() {
+		var identity domain.VCSIdentity
+		var p string
+		if err := rows.Scan(&p, &identity.Login, &identity.UserID, &identity.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identity.Provider = domain.VCSProvider(p)
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// Delete удаляет сопоставление. false - его не было
+func (r *IdentityRepository) Delete(ctx context.Context, provider domain.VCSProvider, login string) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `DELETE FROM vcs_identities WHERE provider = $1 AND login = $2`, string(provider), login)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete identity: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
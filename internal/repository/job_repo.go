@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// JobRepository - общая очередь фоновых задач в таблице jobs.
+// Исполнитель захватывает задачу, записывая в locked_by свой токен, и продлевает аренду locked_until.
+// Все изменения выполняющейся задачи проверяют токен, поэтому задачу, которую отменили
+// или перехватили после истечения аренды, прежний исполнитель уже не изменит
+type JobRepository struct {
+	db *database.DB
+}
+
+func NewJobRepository(db *database.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `id, kind, payload, status, attempts, max_attempts, COALESCE(last_error, ''),
+	run_at, created_at, updated_at, started_at, finished_at`
+
+func scanJob(row pgx.Row) (*domain.Job, error) {
+	var job domain.Job
+	var status string
+	err := row.Scan(&job.ID, &job.Kind, &job.Payload, &status, &job.Attempts, &job.MaxAttempts, &job.LastError,
+		&job.RunAt, &job.CreatedAt, &job.UpdatedAt, &job.StartedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Status = domain.JobState(status)
+	return &job, nil
+}
+
+// Enqueue ставит задачу в очередь. runAt == nil - задача готова к запуску сразу
+func (r *JobRepository) Enqueue(ctx context.Context, kind string, payload []byte, maxAttempts int, runAt *time.Time) (*domain.Job, error) {
+	conn := r.db.Conn(ctx)
+
+	job, err := scanJob(conn.QueryRow(ctx, `
+		INSERT INTO jobs (kind, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, COALESCE($4, CURRENT_TIMESTAMP))
+		RETURNING `+jobColumns,
+		kind, payload, maxAttempts, runAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert job: %w", err)
+	}
+	return job, nil
+}
+
+// InsertClaimed создает задачу сразу захваченной исполнителем с токеном token
+func (r *JobRepository) InsertClaimed(ctx context.Context, kind string, payload []byte, maxAttempts int, token string, lease time.Duration) (*domain.Job, error) {
+	conn := r.db.Conn(ctx)
+
+	job, err := scanJob(conn.QueryRow(ctx, `
+		INSERT INTO jobs (kind, payload, max_attempts, status, attempts, locked_by, locked_until, started_at)
+		VALUES ($1, $2, $3, 'RUNNING', 1, $4, CURRENT_TIMESTAMP + make_interval(secs => $5), CURRENT_TIMESTAMP)
+		RETURNING `+jobColumns,
+		kind, payload, maxAttempts, token, lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert job: %w", err)
+	}
+	return job, nil
+}
+
+// Claim захватывает самую раннюю готовую задачу одного из kinds: ожидающую или
+// выполняющуюся с истекшей арендой (ее исполнитель завершился, не закончив задачу), если у нее остались попытки.
+// SKIP LOCKED позволяет нескольким исполнителям разбирать очередь, не мешая друг другу.
+// nil - подходящих задач нет
+func (r *JobRepository) Claim(ctx context.Context, kinds []string, token string, lease time.Duration) (*domain.Job, error) {
+	conn := r.db.Conn(ctx)
+
+	job, err := scanJob(conn.QueryRow(ctx, `
+		UPDATE jobs
+		SET status = 'RUNNING',
+			attempts = attempts + 1,
+			locked_by = $2,
+			locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3),
+			started_at = COALESCE(started_at, CURRENT_TIMESTAMP),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE kind = ANY($1)
+			AND (
+				(status = 'PENDING' AND run_at <= CURRENT_TIMESTAMP)
+				OR (status = 'RUNNING' AND locked_until < CURRENT_TIMESTAMP AND attempts < max_attempts)
+			)
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		kinds, token, lease.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, nil
+}
+
+// FailExpired переводит в DEAD выполняющиеся задачи kinds с истекшей арендой, у которых не осталось попыток:
+// их исполнитель каждый раз завершался или зависал, не закончив задачу. Возвращает id таких задач
+func (r *JobRepository) FailExpired(ctx context.Context, kinds []string) ([]string, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		UPDATE jobs
+		SET status = 'DEAD',
+			last_error = 'lease expired after ' || attempts || ' attempts',
+			locked_by = NULL,
+			locked_until = NULL,
+			finished_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE kind = ANY($1)
+		AND status = 'RUNNING'
+		AND locked_until < CURRENT_TIMESTAMP
+		AND attempts >= max_attempts
+		RETURNING id
+	`, kinds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail expired jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan job id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Heartbeat продлевает аренду. false - задача больше не принадлежит исполнителю (отменена или перехвачена)
+func (r *JobRepository) Heartbeat(ctx context.Context, jobID, token string, lease time.Duration) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'RUNNING' AND locked_by = $2
+	`, jobID, token, lease.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to extend job lease: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *JobRepository) Complete(ctx context.Context, jobID, token string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'SUCCEEDED', locked_by = NULL, locked_until = NULL,
+			finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'RUNNING' AND locked_by = $2
+	`, jobID, token)
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+	return nil
+}
+
+// Fail записывает ошибку попытки. retryAt - время следующей попытки, nil - задача уходит в DEAD
+func (r *JobRepository) Fail(ctx context.Context, jobID, token, errMsg string, retryAt *time.Time) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET status = CASE WHEN $4::timestamptz IS NULL THEN 'DEAD' ELSE 'PENDING' END,
+			last_error = $3,
+			run_at = COALESCE($4, run_at),
+			locked_by = NULL,
+			locked_until = NULL,
+			finished_at = CASE WHEN $4::timestamptz IS NULL THEN CURRENT_TIMESTAMP END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'RUNNING' AND locked_by = $2
+	`, jobID, token, errMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to record job failure: %w", err)
+	}
+	return nil
+}
+
+// Release возвращает прерванную остановкой задачу в очередь, не засчитывая попытку
+func (r *JobRepository) Release(ctx context.Context, jobID, token string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'PENDING', attempts = GREATEST(attempts - 1, 0), run_at = CURRENT_TIMESTAMP,
+			locked_by = NULL, locked_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'RUNNING' AND locked_by = $2
+	`, jobID, token)
+	if err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) Get(ctx context.Context, jobID string) (*domain.Job, error) {
+	conn := r.db.Conn(ctx)
+
+	job, err := scanJob(conn.QueryRow(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, jobID))
+	if err != nil {
+		return nil, HandleNoRowsError(err)
+	}
+	return job, nil
+}
+
+// List возвращает задачи от новых к старым
+func (r *JobRepository) List(ctx context.Context, filter domain.JobsFilter) ([]domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE TRUE`
+
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		query += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+
+	query += " ORDER BY created_at DESC, id"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// Cancel отменяет ожидающую или выполняющуюся задачу. false - задача уже завершена
+func (r *JobRepository) Cancel(ctx context.Context, jobID string) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'CANCELLED', locked_by = NULL, locked_until = NULL,
+			finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status IN ('PENDING', 'RUNNING')
+	`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Retry возвращает в очередь задачу из DEAD или CANCELLED с обнуленным счетчиком попыток
+func (r *JobRepository) Retry(ctx context.Context, jobID string) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE jobs
+		SET status = 'PENDING', attempts = 0, last_error = NULL, run_at = CURRENT_TIMESTAMP,
+			finished_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status IN ('DEAD', 'CANCELLED')
+	`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry job: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
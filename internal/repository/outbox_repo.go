@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+type OutboxRepository struct {
+	db *database.DB
+}
+
+func NewOutboxRepository(db *database.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Add записывает события. Вызывается внутри транзакции изменения,
+// поэтому события откатываются вместе с ним
+func (r *OutboxRepository) Add(ctx context.Context, events ...domain.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(`
+			INSERT INTO outbox_events (event_type, aggregate_id, payload)
+			VALUES ($1, $2, $3)
+		`, e.Type, e.AggregateID, []byte(e.Payload))
+	}
+
+	conn := r.db.Conn(ctx)
+	if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write outbox events: %w", err)
+	}
+	return nil
+}
+
+// LockPending блокирует до limit готовых к отправке событий в порядке id.
+// Вызывается в транзакции; SKIP LOCKED позволяет нескольким релеям не отправлять одно событие одновременно
+func (r *OutboxRepository) LockPending(ctx context.Context, limit int) ([]domain.OutboxEvent, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT id, event_type, aggregate_id, payload, created_at, attempts
+		FROM outbox_events
+		WHERE published_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var e domain.OutboxEvent
+		var eventType string
+		if err := rows.Scan(&e.ID, &eventType, &e.AggregateID, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		e.Type = domain.EventType(eventType)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	conn := r.db.Conn(ctx)
+	_, err := conn.Exec(ctx, `
+		UPDATE outbox_events
+		SET published_at = CURRENT_TIMESTAMP, last_error = NULL
+		WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox events published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed откладывает следующую попытку отправки события до nextAttemptAt
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, nextAttemptAt time.Time) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1
+	`, id, errMsg, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to record outbox event failure: %w", err)
+	}
+	return nil
+}
+
+// DeletePublishedBefore удаляет опубликованные события старше before
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"ynastt/avito_test_task_backend_2025/internal/domain"
 	"ynastt/avito_test_task_backend_2025/pkg/database"
-
-	"github.com/lib/pq"
 )
 
 type PullRequestRepository struct {
@@ -19,72 +19,61 @@ func NewPullRequestRepository(db *database.DB) *PullRequestRepository {
 	return &PullRequestRepository{db: db}
 }
 
-func (r *PullRequestRepository) CreatePullRequest(ctx context.Context, pr domain.CreatePRRequest) (time.Time, error) {
+// CreatePullRequest создает PR сразу с назначенными ревьюерами одним запросом
+func (r *PullRequestRepository) CreatePullRequest(ctx context.Context, pr domain.CreatePRRequest, reviewerIDs []string) (*domain.PullRequest, error) {
 	conn := r.db.Conn(ctx)
 
-	var createdAt time.Time
-	err := conn.QueryRowContext(ctx, `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-		VALUES ($1, $2, $3, $4)
-		RETURNING created_at
-	`, pr.ID, pr.Name, pr.AuthorID, domain.PRStatusOpen).Scan(&createdAt)
+	var created domain.PullRequest
+	var status string
+	err := conn.QueryRow(ctx, `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, required_areas)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::TEXT[], '{}'))
+		RETURNING pull_request_id, pull_request_name, author_id, status, assigned_reviewers, required_areas, created_at, merged_at, version
+	`, pr.ID, pr.Name, pr.AuthorID, domain.PRStatusOpen, reviewerIDs, pr.RequiredAreas).Scan(
+		&created.ID, &created.Name, &created.AuthorID, &status, &created.AssignedReviewers, &created.RequiredAreas,
+		&created.CreatedAt, &created.MergedAt, &created.Version)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to insert PR: %w", err)
+		if err := HandleUniqueViolation(err); err == ErrAlreadyExists {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to insert PR: %w", err)
 	}
 
-	return createdAt, nil
+	created.Status = domain.PRStatus(status)
+	return &created, nil
 }
 
 func (r *PullRequestRepository) Exists(ctx context.Context, prID string) (bool, error) {
 	conn := r.db.Conn(ctx)
 	var exists bool
-	err := conn.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID).Scan(&exists)
+	err := conn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)", prID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check pr existence: %w", err)
 	}
 	return exists, nil
 }
 
-func (r *PullRequestRepository) AssignReviewer(ctx context.Context, reviewerID, prID string) error {
-	conn := r.db.Conn(ctx)
-
-	_, err := conn.ExecContext(ctx, `
-		UPDATE pull_requests 
-		SET assigned_reviewers = array_append(assigned_reviewers, $1)
-		WHERE pull_request_id = $2
-	`, reviewerID, prID)
-	if err != nil {
-		return fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
-	}
-
-	return nil
+func (r *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.getPullRequest(ctx, prID, "")
 }
 
-func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, reviewerID, prID string) error {
-	conn := r.db.Conn(ctx)
-
-	_, err := conn.ExecContext(ctx, `
-		UPDATE pull_requests 
-		SET assigned_reviewers = array_remove(assigned_reviewers, $1)
-		WHERE pull_request_id = $2
-	`, reviewerID, prID)
-	if err != nil {
-		return fmt.Errorf("failed to delete reviewer: %w", err)
-	}
-
-	return nil
+// GetPullRequestByIDForUpdate читает PR и блокирует его строку до конца транзакции,
+// чтобы параллельные переназначения, merge и деактивации меняли PR по очереди
+func (r *PullRequestRepository) GetPullRequestByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return r.getPullRequest(ctx, prID, " FOR UPDATE")
 }
 
-func (r *PullRequestRepository) GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error) {
+func (r *PullRequestRepository) getPullRequest(ctx context.Context, prID, lockClause string) (*domain.PullRequest, error) {
 	conn := r.db.Conn(ctx)
 
 	var pr domain.PullRequest
 	var status string
-	err := conn.QueryRowContext(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at
+	err := conn.QueryRow(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, required_areas, created_at, merged_at, version
 		FROM pull_requests
 		WHERE pull_request_id = $1
-	`, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status, pq.Array(&pr.AssignedReviewers), &pr.CreatedAt, &pr.MergedAt)
+	`+lockClause, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.AssignedReviewers, &pr.RequiredAreas,
+		&pr.CreatedAt, &pr.MergedAt, &pr.Version)
 
 	if err != nil {
 		return nil, HandleNoRowsError(err)
@@ -98,9 +87,9 @@ func (r *PullRequestRepository) MergePullRequest(ctx context.Context, prID strin
 	conn := r.db.Conn(ctx)
 	now := time.Now()
 
-	_, err := conn.ExecContext(ctx, `
+	_, err := conn.Exec(ctx, `
 		UPDATE pull_requests
-		SET status = $1, merged_at = $2
+		SET status = $1, merged_at = $2, version = version + 1
 		WHERE pull_request_id = $3
 	`, domain.PRStatusMerged, now, prID)
 
@@ -111,13 +100,40 @@ func (r *PullRequestRepository) MergePullRequest(ctx context.Context, prID strin
 	return nil
 }
 
-func (r *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	conn := r.db.Conn(ctx)
-	rows, err := conn.QueryContext(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status
+func (r *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, userID string, filter domain.ReviewerPRsFilter) ([]domain.PullRequestShort, error) {
+	query := `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at
 		FROM pull_requests
 		WHERE $1 = ANY(assigned_reviewers)
-	`, userID)
+	`
+
+	var args []interface{}
+	args = append(args, userID)
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		query += fmt.Sprintf(" AND (created_at, pull_request_id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	query += " ORDER BY created_at, pull_request_id"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query PRs: %w", err)
 	}
@@ -127,7 +143,7 @@ func (r *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, u
 	for rows.Next() {
 		var pr domain.PullRequestShort
 		var status string
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan PR: %w", err)
 		}
 		pr.Status = domain.PRStatus(status)
@@ -137,14 +153,52 @@ func (r *PullRequestRepository) GetPullRequestsByReviewer(ctx context.Context, u
 	return prs, rows.Err()
 }
 
+// CountRecentReviewsByAuthor считает, сколько PR автора, созданных не раньше since, назначено каждому ревьюеру
+func (r *PullRequestRepository) CountRecentReviewsByAuthor(ctx context.Context, authorID string, since time.Time) (map[string]int, error) {
+	conn := r.db.Conn(ctx)
+	rows, err := conn.Query(ctx, `
+		SELECT reviewer_id, COUNT(*)
+		FROM pull_requests, unnest(assigned_reviewers) AS reviewer_id
+		WHERE author_id = $1 AND created_at >= $2
+		GROUP BY reviewer_id
+	`, authorID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reviewerID string
+		var count int
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan recent reviews: %w", err)
+		}
+		counts[reviewerID] = count
+	}
+
+	return counts, rows.Err()
+}
+
 func (r *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+	return r.getOpenPullRequestsByReviewer(ctx, userID, "")
+}
+
+// GetOpenPullRequestsByReviewerForUpdate блокирует открытые PR ревьюера до конца транзакции.
+// Строки блокируются в порядке pull_request_id, поэтому параллельные деактивации не взаимоблокируются
+func (r *PullRequestRepository) GetOpenPullRequestsByReviewerForUpdate(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
+	return r.getOpenPullRequestsByReviewer(ctx, userID, " FOR UPDATE")
+}
+
+func (r *PullRequestRepository) getOpenPullRequestsByReviewer(ctx context.Context, userID, lockClause string) ([]domain.PullRequestShort, error) {
 	conn := r.db.Conn(ctx)
-	rows, err := conn.QueryContext(ctx, `
+	rows, err := conn.Query(ctx, `
 		SELECT pull_request_id, pull_request_name, author_id, status
 		FROM pull_requests
 		WHERE $1 = ANY(assigned_reviewers)
 		AND status = 'OPEN'
-	`, userID)
+		ORDER BY pull_request_id
+	`+lockClause, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query open PRs: %w", err)
 	}
@@ -167,7 +221,7 @@ func (r *PullRequestRepository) GetOpenPullRequestsByReviewer(ctx context.Contex
 func (r *PullRequestRepository) IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error) {
 	conn := r.db.Conn(ctx)
 	var exists bool
-	err := conn.QueryRowContext(ctx, `
+	err := conn.QueryRow(ctx, `
 		SELECT EXISTS(
 			SELECT 1 FROM pull_requests
 			WHERE pull_request_id = $1 
@@ -176,3 +230,74 @@ func (r *PullRequestRepository) IsReviewerAssigned(ctx context.Context, prID, us
 	`, prID, userID).Scan(&exists)
 	return exists, err
 }
+
+// SetStatus переводит PR между OPEN и CLOSED
+func (r *PullRequestRepository) SetStatus(ctx context.Context, prID string, status domain.PRStatus) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE pull_requests
+		SET status = $2, version = version + 1
+		WHERE pull_request_id = $1
+	`, prID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update PR status: %w", err)
+	}
+
+	return nil
+}
+
+// AddReviewer добавляет ревьюера в конец списка назначенных
+func (r *PullRequestRepository) AddReviewer(ctx context.Context, prID, reviewerID string) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE pull_requests
+		SET assigned_reviewers = array_append(COALESCE(assigned_reviewers, '{}'), $2), version = version + 1
+		WHERE pull_request_id = $1
+	`, prID, reviewerID)
+	if err != nil {
+		return fmt.Errorf("failed to add reviewer: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyReviewerChanges применяет замены ревьюеров и записывает их в историю
+// одним батчем, без отдельного сетевого запроса на каждое изменение
+func (r *PullRequestRepository) ApplyReviewerChanges(ctx context.Context, changes []domain.ReviewerChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, ch := range changes {
+		var newReviewer *string
+		if ch.NewReviewerID != "" {
+			newReviewer = &ch.NewReviewerID
+			batch.Queue(`
+				UPDATE pull_requests
+				SET assigned_reviewers = array_replace(assigned_reviewers, $1, $2), version = version + 1
+				WHERE pull_request_id = $3
+			`, ch.OldReviewerID, ch.NewReviewerID, ch.PRID)
+		} else {
+			batch.Queue(`
+				UPDATE pull_requests
+				SET assigned_reviewers = array_remove(assigned_reviewers, $1), version = version + 1
+				WHERE pull_request_id = $2
+			`, ch.OldReviewerID, ch.PRID)
+		}
+
+		batch.Queue(`
+			INSERT INTO pr_reassignments (pull_request_id, old_reviewer_id, new_reviewer_id, reason)
+			VALUES ($1, $2, $3, $4)
+		`, ch.PRID, ch.OldReviewerID, newReviewer, ch.Reason)
+	}
+
+	conn := r.db.Conn(ctx)
+	if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to apply reviewer changes: %w", err)
+	}
+
+	return nil
+}
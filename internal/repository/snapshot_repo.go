@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+type SnapshotRepository struct {
+	db *database.DB
+}
+
+func NewSnapshotRepository(db *database.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+func (r *SnapshotRepository) IsEmpty(ctx context.Context) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	var notEmpty bool
+	err := conn.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM teams)
+			OR EXISTS(SELECT 1 FROM users)
+			OR EXISTS(SELECT 1 FROM pull_requests)
+	`).Scan(&notEmpty)
+	if err != nil {
+		return false, fmt.Errorf("failed to check database emptiness: %w", err)
+	}
+	return !notEmpty, nil
+}
+
+func (r *SnapshotRepository) ListTeams(ctx context.Context) ([]domain.SnapshotTeam, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT team_name, created_at, updated_at, version
+		FROM teams
+		ORDER BY team_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []domain.SnapshotTeam
+	for rows.Next() {
+		var t domain.SnapshotTeam
+		if err := rows.Scan(&t.TeamName, &t.CreatedAt, &t.UpdatedAt, &t.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, t)
+	}
+
+	return teams, rows.Err()
+}
+
+func (r *SnapshotRepository) ListUsers(ctx context.Context) ([]domain.SnapshotUser, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active, skills, created_at, updated_at
+		FROM users
+		ORDER BY user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.SnapshotUser
+	for rows.Next() {
+		var u domain.SnapshotUser
+		if err := rows.Scan(&u.UserID, &u.Username, &u.TeamName, &u.IsActive, &u.Skills, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *SnapshotRepository) ListPullRequests(ctx context.Context) ([]domain.SnapshotPR, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, required_areas, created_at, merged_at, version
+		FROM pull_requests
+		ORDER BY created_at, pull_request_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PRs: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []domain.SnapshotPR
+	for rows.Next() {
+		var pr domain.SnapshotPR
+		var status string
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &status, &pr.AssignedReviewers, &pr.RequiredAreas,
+			&pr.CreatedAt, &pr.MergedAt, &pr.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		pr.Status = domain.PRStatus(status)
+		if pr.AssignedReviewers == nil {
+			pr.AssignedReviewers = []string{}
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+func (r *SnapshotRepository) ListReassignments(ctx context.Context) ([]domain.SnapshotReassignment, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT pull_request_id, old_reviewer_id, new_reviewer_id, reason, created_at
+		FROM pr_reassignments
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reassignments: %w", err)
+	}
+	defer rows.Close()
+
+	var reassignments []domain.SnapshotReassignment
+	for rows.Next() {
+		var ra domain.SnapshotReassignment
+		var reason string
+		if err := rows.Scan(&ra.PRID, &ra.OldReviewerID, &ra.NewReviewerID, &reason, &ra.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reassignment: %w", err)
+		}
+		ra.Reason = domain.ReassignReason(reason)
+		reassignments = append(reassignments, ra)
+	}
+
+	return reassignments, rows.Err()
+}
+
+func (r *SnapshotRepository) ListCodeOwners(ctx context.Context) ([]domain.SnapshotCodeOwners, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT team_name, content, updated_at
+		FROM team_codeowners
+		ORDER BY team_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CODEOWNERS: %w", err)
+	}
+	defer rows.Close()
+
+	var codeOwners []domain.SnapshotCodeOwners
+	for rows.Next() {
+		var co domain.SnapshotCodeOwners
+		if err := rows.Scan(&co.TeamName, &co.Content, &co.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan CODEOWNERS: %w", err)
+		}
+		codeOwners = append(codeOwners, co)
+	}
+
+	return codeOwners, rows.Err()
+}
+
+func (r *SnapshotRepository) InsertTeam(ctx context.Context, t domain.SnapshotTeam) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO teams (team_name, created_at, updated_at, version)
+		VALUES ($1, $2, $3, GREATEST($4, 1))
+	`, t.TeamName, t.CreatedAt, t.UpdatedAt, t.Version)
+	if err != nil {
+		return fmt.Errorf("failed to insert team %s: %w", t.TeamName, err)
+	}
+	return nil
+}
+
+func (r *SnapshotRepository) InsertUser(ctx context.Context, u domain.SnapshotUser) error {
+	conn := r.db.Conn(ctx)
+
+	var teamName *string
+	if u.TeamName != "" {
+		teamName = &u.TeamName
+	}
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO users (user_id, username, team_name, is_active, skills, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5::TEXT[], '{}'), $6, $7)
+	`, u.UserID, u.Username, teamName, u.IsActive, u.Skills, u.CreatedAt, u.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert user %s: %w", u.UserID, err)
+	}
+	return nil
+}
+
+func (r *SnapshotRepository) InsertPullRequest(ctx context.Context, pr domain.SnapshotPR) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, assigned_reviewers, required_areas,
+			created_at, merged_at, version)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::TEXT[], '{}'), COALESCE($7, CURRENT_TIMESTAMP), $8, GREATEST($9, 1))
+	`, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.AssignedReviewers, pr.RequiredAreas, pr.CreatedAt, pr.MergedAt, pr.Version)
+	if err != nil {
+		return fmt.Errorf("failed to insert PR %s: %w", pr.ID, err)
+	}
+	return nil
+}
+
+func (r *SnapshotRepository) InsertReassignment(ctx context.Context, ra domain.SnapshotReassignment) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO pr_reassignments (pull_request_id, old_reviewer_id, new_reviewer_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, ra.PRID, ra.OldReviewerID, ra.NewReviewerID, ra.Reason, ra.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert reassignment for PR %s: %w", ra.PRID, err)
+	}
+	return nil
+}
+
+func (r *SnapshotRepository) InsertCodeOwners(ctx context.Context, co domain.SnapshotCodeOwners) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO team_codeowners (team_name, content, updated_at)
+		VALUES ($1, $2, $3)
+	`, co.TeamName, co.Content, co.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert CODEOWNERS for team %s: %w", co.TeamName, err)
+	}
+	return nil
+}
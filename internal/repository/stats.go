@@ -9,8 +9,13 @@ import (
 
 type StatsRepository interface {
 	GetTotalStats(ctx context.Context) (*domain.StatsResponse, error)
-	GetUserAssignmentStats(ctx context.Context) ([]domain.UserAssignmentStats, error)
-	GetPRAssignmentStats(ctx context.Context) ([]domain.PRAssignmentStats, error)
+	GetWindowStats(ctx context.Context, filter domain.StatsFilter) (*domain.WindowStats, error)
+	GetUserAssignmentStats(ctx context.Context, filter domain.StatsFilter) ([]domain.UserAssignmentStats, error)
+	GetPRAssignmentStats(ctx context.Context, filter domain.StatsFilter) ([]domain.PRAssignmentStats, error)
+	StreamUserAssignmentStats(ctx context.Context, filter domain.StatsFilter, fn func(domain.UserAssignmentStats) error) error
+	StreamPRAssignmentStats(ctx context.Context, filter domain.StatsFilter, fn func(domain.PRAssignmentStats) error) error
+	GetTeamCycleTime(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamCycleTime, error)
+	GetReviewerCycleTime(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerCycleTime, error)
 }
 
 type statsRepository struct {
@@ -27,13 +32,14 @@ func (r *statsRepository) GetTotalStats(ctx context.Context) (*domain.StatsRespo
 	var stats domain.StatsResponse
 
 	// Основная статистика
-	err := conn.QueryRowContext(ctx, `
+	err := conn.QueryRow(ctx, `
         SELECT 
             (SELECT COUNT(*) FROM teams) as total_teams,
             (SELECT COUNT(*) FROM users) as total_users,
             (SELECT COUNT(*) FROM pull_requests) as total_prs,
             (SELECT COUNT(*) FROM pull_requests WHERE status = 'OPEN') as open_prs,
             (SELECT COUNT(*) FROM pull_requests WHERE status = 'MERGED') as merged_prs,
+            (SELECT COUNT(*) FROM pull_requests WHERE status = 'CLOSED') as closed_prs,
             (SELECT COUNT(*) FROM users WHERE is_active = true) as active_users,
             (SELECT COUNT(*) FROM users WHERE is_active = false) as inactive_users
     `).Scan(
@@ -42,6 +48,7 @@ func (r *statsRepository) GetTotalStats(ctx context.Context) (*domain.StatsRespo
 		&stats.TotalPRs,
 		&stats.OpenPRs,
 		&stats.MergedPRs,
+		&stats.ClosedPRs,
 		&stats.ActiveUsers,
 		&stats.InactiveUsers,
 	)
@@ -53,10 +60,72 @@ func (r *statsRepository) GetTotalStats(ctx context.Context) (*domain.StatsRespo
 	return &stats, nil
 }
 
-func (r *statsRepository) GetUserAssignmentStats(ctx context.Context) ([]domain.UserAssignmentStats, error) {
+// Статистика за окно [from, to) по команде автора PR.
+// Пустые границы окна и пустое имя команды не ограничивают выборку
+func (r *statsRepository) GetWindowStats(ctx context.Context, filter domain.StatsFilter) (*domain.WindowStats, error) {
 	conn := r.db.Conn(ctx)
 
-	rows, err := conn.QueryContext(ctx, `
+	stats := domain.WindowStats{
+		From:     filter.From,
+		To:       filter.To,
+		TeamName: filter.TeamName,
+	}
+
+	err := conn.QueryRow(ctx, `
+        WITH team_prs AS (
+            SELECT pr.pull_request_id, pr.assigned_reviewers, pr.created_at, pr.merged_at
+            FROM pull_requests pr
+            JOIN users a ON a.user_id = pr.author_id
+            WHERE ($3 = '' OR a.team_name = $3)
+        )
+        SELECT
+            (SELECT COUNT(*) FROM team_prs
+                WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+                AND ($2::timestamptz IS NULL OR created_at < $2)) as prs_created,
+            (SELECT COUNT(*) FROM team_prs
+                WHERE merged_at IS NOT NULL
+                AND ($1::timestamptz IS NULL OR merged_at >= $1)
+                AND ($2::timestamptz IS NULL OR merged_at < $2)) as prs_merged,
+            (SELECT COUNT(*) FROM pr_reassignments ra
+                JOIN team_prs tp ON tp.pull_request_id = ra.pull_request_id
+                WHERE ($1::timestamptz IS NULL OR ra.created_at >= $1)
+                AND ($2::timestamptz IS NULL OR ra.created_at < $2)) as reassignments,
+            (SELECT COUNT(DISTINCT reviewer_id) FROM team_prs, unnest(assigned_reviewers) AS reviewer_id
+                WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+                AND ($2::timestamptz IS NULL OR created_at < $2)) as active_reviewers
+    `, filter.From, filter.To, filter.TeamName).Scan(
+		&stats.PRsCreated,
+		&stats.PRsMerged,
+		&stats.Reassignments,
+		&stats.ActiveReviewers,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func (r *statsRepository) GetUserAssignmentStats(ctx context.Context, filter domain.StatsFilter) ([]domain.UserAssignmentStats, error) {
+	var stats []domain.UserAssignmentStats
+	err := r.StreamUserAssignmentStats(ctx, filter, func(s domain.UserAssignmentStats) error {
+		stats = append(stats, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// StreamUserAssignmentStats вызывает fn для каждой строки выборки, не накапливая ее в памяти.
+// Ошибка из fn прерывает чтение и возвращается как есть
+func (r *statsRepository) StreamUserAssignmentStats(ctx context.Context, filter domain.StatsFilter, fn func(domain.UserAssignmentStats) error) error {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
         SELECT 
             u.user_id,
             u.username,
@@ -65,54 +134,74 @@ func (r *statsRepository) GetUserAssignmentStats(ctx context.Context) ([]domain.
             COUNT(pr.pull_request_id) as pr_count
         FROM users u
         LEFT JOIN pull_requests pr ON u.user_id = ANY(pr.assigned_reviewers)
+            AND ($1::timestamptz IS NULL OR pr.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+        WHERE ($3 = '' OR u.team_name = $3)
         GROUP BY u.user_id, u.username, u.team_name, u.is_active
         ORDER BY pr_count DESC, u.user_id
-    `)
+    `, filter.From, filter.To, filter.TeamName)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var stats []domain.UserAssignmentStats
 	for rows.Next() {
 		var s domain.UserAssignmentStats
 		err := rows.Scan(&s.UserID, &s.Username, &s.TeamName, &s.IsActive, &s.PRCount)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(s); err != nil {
+			return err
 		}
+	}
+
+	return rows.Err()
+}
+
+func (r *statsRepository) GetPRAssignmentStats(ctx context.Context, filter domain.StatsFilter) ([]domain.PRAssignmentStats, error) {
+	var stats []domain.PRAssignmentStats
+	err := r.StreamPRAssignmentStats(ctx, filter, func(s domain.PRAssignmentStats) error {
 		stats = append(stats, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return stats, rows.Err()
+	return stats, nil
 }
 
-func (r *statsRepository) GetPRAssignmentStats(ctx context.Context) ([]domain.PRAssignmentStats, error) {
+func (r *statsRepository) StreamPRAssignmentStats(ctx context.Context, filter domain.StatsFilter, fn func(domain.PRAssignmentStats) error) error {
 	conn := r.db.Conn(ctx)
 
-	rows, err := conn.QueryContext(ctx, `
+	rows, err := conn.Query(ctx, `
         SELECT 
-            pull_request_id,
-            pull_request_name,
-            author_id,
-            status,
-            array_length(assigned_reviewers, 1) as reviewers_count
-        FROM pull_requests
-        ORDER BY created_at DESC
-    `)
+            pr.pull_request_id,
+            pr.pull_request_name,
+            pr.author_id,
+            pr.status,
+            array_length(pr.assigned_reviewers, 1) as reviewers_count
+        FROM pull_requests pr
+        JOIN users a ON a.user_id = pr.author_id
+        WHERE ($1::timestamptz IS NULL OR pr.created_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.created_at < $2)
+            AND ($3 = '' OR a.team_name = $3)
+        ORDER BY pr.created_at DESC
+    `, filter.From, filter.To, filter.TeamName)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var stats []domain.PRAssignmentStats
 	for rows.Next() {
 		var s domain.PRAssignmentStats
 		var reviewersCount *int
 		err := rows.Scan(&s.PRID, &s.PRName, &s.AuthorID, &s.Status, &reviewersCount)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if reviewersCount != nil {
@@ -121,6 +210,92 @@ func (r *statsRepository) GetPRAssignmentStats(ctx context.Context) ([]domain.PR
 			s.Reviewers = 0
 		}
 
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Перцентили времени от создания до merge по командам авторов.
+// В окно [from, to) попадают PR, смерженные в этот период
+func (r *statsRepository) GetTeamCycleTime(ctx context.Context, filter domain.StatsFilter) ([]domain.TeamCycleTime, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+        SELECT
+            a.team_name,
+            COUNT(*) as merged_count,
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p50,
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p90,
+            percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p99
+        FROM pull_requests pr
+        JOIN users a ON a.user_id = pr.author_id
+        WHERE pr.status = 'MERGED' AND pr.merged_at IS NOT NULL
+            AND ($1::timestamptz IS NULL OR pr.merged_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.merged_at < $2)
+            AND ($3 = '' OR a.team_name = $3)
+        GROUP BY a.team_name
+        ORDER BY a.team_name
+    `, filter.From, filter.To, filter.TeamName)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.TeamCycleTime
+	for rows.Next() {
+		var s domain.TeamCycleTime
+		err := rows.Scan(&s.TeamName, &s.TimeToMerge.Count, &s.TimeToMerge.P50, &s.TimeToMerge.P90, &s.TimeToMerge.P99)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// Перцентили времени от создания до merge по текущим ревьюерам смерженных PR
+func (r *statsRepository) GetReviewerCycleTime(ctx context.Context, filter domain.StatsFilter) ([]domain.ReviewerCycleTime, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+        SELECT
+            rv.reviewer_id,
+            COALESCE(u.username, '') as username,
+            COALESCE(u.team_name, '') as team_name,
+            COUNT(*) as merged_count,
+            percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p50,
+            percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p90,
+            percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)) as p99
+        FROM pull_requests pr
+        JOIN users a ON a.user_id = pr.author_id
+        CROSS JOIN LATERAL unnest(pr.assigned_reviewers) AS rv(reviewer_id)
+        LEFT JOIN users u ON u.user_id = rv.reviewer_id
+        WHERE pr.status = 'MERGED' AND pr.merged_at IS NOT NULL
+            AND ($1::timestamptz IS NULL OR pr.merged_at >= $1)
+            AND ($2::timestamptz IS NULL OR pr.merged_at < $2)
+            AND ($3 = '' OR a.team_name = $3)
+        GROUP BY rv.reviewer_id, u.username, u.team_name
+        ORDER BY rv.reviewer_id
+    `, filter.From, filter.To, filter.TeamName)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []domain.ReviewerCycleTime
+	for rows.Next() {
+		var s domain.ReviewerCycleTime
+		err := rows.Scan(&s.UserID, &s.Username, &s.TeamName,
+			&s.TimeToMerge.Count, &s.TimeToMerge.P50, &s.TimeToMerge.P90, &s.TimeToMerge.P99)
+		if err != nil {
+			return nil, err
+		}
 		stats = append(stats, s)
 	}
 
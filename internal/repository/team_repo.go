@@ -19,7 +19,7 @@ func NewTeamRepository(db *database.DB) *TeamRepository {
 func (r *TeamRepository) CreateTeam(ctx context.Context, teamName string) error {
 	conn := r.db.Conn(ctx)
 
-	_, err := conn.ExecContext(ctx, "INSERT INTO teams (team_name) VALUES ($1)", teamName)
+	_, err := conn.Exec(ctx, "INSERT INTO teams (team_name) VALUES ($1)", teamName)
 	if err != nil {
 		return fmt.Errorf("failed to insert team: %w", err)
 	}
@@ -31,24 +31,68 @@ func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, err
 	conn := r.db.Conn(ctx)
 
 	var exists bool
-	err := conn.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", teamName).Scan(&exists)
+	err := conn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", teamName).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check team existence: %w", err)
 	}
 	return exists, nil
 }
 
-func (r *TeamRepository) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
-	exists, err := r.Exists(ctx, teamName)
+func (r *TeamRepository) GetVersion(ctx context.Context, teamName string) (int64, error) {
+	return r.getVersion(ctx, teamName, "")
+}
+
+// GetVersionForUpdate возвращает версию команды и блокирует ее строку до конца транзакции
+func (r *TeamRepository) GetVersionForUpdate(ctx context.Context, teamName string) (int64, error) {
+	return r.getVersion(ctx, teamName, " FOR UPDATE")
+}
+
+func (r *TeamRepository) getVersion(ctx context.Context, teamName, lockClause string) (int64, error) {
+	conn := r.db.Conn(ctx)
+
+	var version int64
+	err := conn.QueryRow(ctx, "SELECT version FROM teams WHERE team_name = $1"+lockClause, teamName).Scan(&version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check team existence: %w", err)
+		return 0, HandleNoRowsError(err)
 	}
-	if !exists {
-		return nil, ErrNotFound
+	return version, nil
+}
+
+// BumpVersions увеличивает версии команд. Строки обновляются в порядке имен, чтобы не было взаимоблокировок
+func (r *TeamRepository) BumpVersions(ctx context.Context, teamNames []string) error {
+	if len(teamNames) == 0 {
+		return nil
+	}
+
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		WITH locked AS (
+			SELECT team_name FROM teams
+			WHERE team_name = ANY($1)
+			ORDER BY team_name
+			FOR UPDATE
+		)
+		UPDATE teams t
+		SET version = t.version + 1, updated_at = NOW()
+		FROM locked
+		WHERE t.team_name = locked.team_name
+	`, teamNames)
+	if err != nil {
+		return fmt.Errorf("failed to bump team versions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamRepository) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+	version, err := r.GetVersion(ctx, teamName)
+	if err != nil {
+		return nil, err
 	}
 
 	conn := r.db.Conn(ctx)
-	rows, err := conn.QueryContext(ctx, `
+	rows, err := conn.Query(ctx, `
 		SELECT user_id, username, is_active
 		FROM users
 		WHERE team_name = $1
@@ -74,5 +118,6 @@ func (r *TeamRepository) GetTeam(ctx context.Context, teamName string) (*domain.
 	return &domain.Team{
 		TeamName: teamName,
 		Members:  members,
+		Version:  version,
 	}, nil
 }
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/lib/pq"
-
 	"ynastt/avito_test_task_backend_2025/internal/domain"
 	"ynastt/avito_test_task_backend_2025/pkg/database"
 )
@@ -18,10 +16,17 @@ func NewUserRepository(db *database.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) Upsert(ctx context.Context, user domain.TeamMember, teamName string) error {
+// Upsert создает или обновляет пользователя и возвращает команду, в которой он состоял до этого
+// (пустая строка для нового пользователя). Прежняя строка блокируется FOR NO KEY UPDATE, а не FOR UPDATE,
+// чтобы параллельный подбор ревьюеров не пропускал пользователя (см. GetActiveUsersByTeam)
+func (r *UserRepository) Upsert(ctx context.Context, user domain.TeamMember, teamName string) (string, error) {
 	conn := r.db.Conn(ctx)
 
-	_, err := conn.ExecContext(ctx, `
+	var prevTeam *string
+	err := conn.QueryRow(ctx, `
+        WITH prev AS (
+            SELECT team_name FROM users WHERE user_id = $1 FOR NO KEY UPDATE
+        )
         INSERT INTO users (user_id, username, team_name, is_active)
         VALUES ($1, $2, $3, $4)
         ON CONFLICT (user_id) DO UPDATE
@@ -29,24 +34,37 @@ func (r *UserRepository) Upsert(ctx context.Context, user domain.TeamMember, tea
             team_name = EXCLUDED.team_name,
             is_active = EXCLUDED.is_active,
             updated_at = NOW()
-    `, user.UserID, user.Username, teamName, user.IsActive)
+        RETURNING (SELECT team_name FROM prev)
+    `, user.UserID, user.Username, teamName, user.IsActive).Scan(&prevTeam)
 
 	if err != nil {
-		return fmt.Errorf("failed to upsert user %s: %w", user.UserID, err)
+		return "", fmt.Errorf("failed to upsert user %s: %w", user.UserID, err)
 	}
 
-	return nil
+	if prevTeam == nil {
+		return "", nil
+	}
+	return *prevTeam, nil
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	return r.getByID(ctx, userID, "")
+}
+
+// GetByIDForUpdate читает пользователя и блокирует его строку до конца транзакции
+func (r *UserRepository) GetByIDForUpdate(ctx context.Context, userID string) (*domain.User, error) {
+	return r.getByID(ctx, userID, " FOR UPDATE")
+}
+
+func (r *UserRepository) getByID(ctx context.Context, userID, lockClause string) (*domain.User, error) {
 	conn := r.db.Conn(ctx)
 
 	var user domain.User
-	err := conn.QueryRowContext(ctx, `
-		SELECT user_id, username, team_name, is_active
+	err := conn.QueryRow(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active, skills
 		FROM users
 		WHERE user_id = $1
-	`, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+	`+lockClause, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills)
 
 	if err != nil {
 		return nil, HandleNoRowsError(err)
@@ -55,16 +73,59 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (*domain.Us
 	return &user, nil
 }
 
+func (r *UserRepository) GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT user_id, username, COALESCE(team_name, ''), is_active, skills
+		FROM users
+		WHERE user_id = ANY($1)
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
 func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
 	conn := r.db.Conn(ctx)
 
 	var user domain.User
-	err := conn.QueryRowContext(ctx, `
+	err := conn.QueryRow(ctx, `
 		UPDATE users
 		SET is_active = $1, updated_at = NOW()
 		WHERE user_id = $2
-		RETURNING user_id, username, team_name, is_active
-	`, isActive, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
+		RETURNING user_id, username, COALESCE(team_name, ''), is_active, skills
+	`, isActive, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills)
+
+	if err != nil {
+		return nil, HandleNoRowsError(err)
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) SetSkills(ctx context.Context, userID string, skills []string) (*domain.User, error) {
+	conn := r.db.Conn(ctx)
+
+	var user domain.User
+	err := conn.QueryRow(ctx, `
+		UPDATE users
+		SET skills = $1, updated_at = NOW()
+		WHERE user_id = $2
+		RETURNING user_id, username, COALESCE(team_name, ''), is_active, skills
+	`, skills, userID).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills)
 
 	if err != nil {
 		return nil, HandleNoRowsError(err)
@@ -76,8 +137,8 @@ func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActiv
 func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
 	conn := r.db.Conn(ctx)
 
-	rows, err := conn.QueryContext(ctx, `
-		SELECT user_id, username, team_name, is_active
+	rows, err := conn.Query(ctx, `
+		SELECT user_id, username, team_name, is_active, skills
 		FROM users
 		WHERE team_name = $1
 	`, teamName)
@@ -89,7 +150,7 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]doma
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
@@ -98,9 +159,14 @@ func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]doma
 	return users, rows.Err()
 }
 
+// GetActiveUsersByTeam возвращает активных участников команды и внутри транзакции удерживает их строки
+// в режиме FOR KEY SHARE: пока кандидат назначается ревьюером, его нельзя деактивировать.
+// FOR KEY SHARE конфликтует только с FOR UPDATE, которую берет деактивация (GetByIDForUpdate),
+// поэтому SKIP LOCKED пропускает лишь пользователей, которых прямо сейчас деактивируют.
+// Обычные изменения строки (навыки, активация, Upsert) кандидата не скрывают
 func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error) {
 	query := `
-		SELECT user_id, username, team_name, is_active
+		SELECT user_id, username, team_name, is_active, skills
 		FROM users
 		WHERE team_name = $1 AND is_active = TRUE
 	`
@@ -110,11 +176,12 @@ func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamName stri
 
 	if len(excludeUserIDs) > 0 {
 		query += " AND NOT (user_id = ANY($2))"
-		args = append(args, pq.Array(excludeUserIDs))
+		args = append(args, excludeUserIDs)
 	}
+	query += " ORDER BY user_id FOR KEY SHARE SKIP LOCKED"
 
 	conn := r.db.Conn(ctx)
-	rows, err := conn.QueryContext(ctx, query, args...)
+	rows, err := conn.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active users: %w", err)
 	}
@@ -123,7 +190,7 @@ func (r *UserRepository) GetActiveUsersByTeam(ctx context.Context, teamName stri
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &user.Skills); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
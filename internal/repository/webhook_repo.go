@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+type WebhookRepository struct {
+	db *database.DB
+}
+
+func NewWebhookRepository(db *database.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const subscriptionColumns = `id, url, secret, event_types, is_active, created_at`
+
+func scanSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	var eventTypes []string
+	if err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &eventTypes, &sub.IsActive, &sub.CreatedAt); err != nil {
+		return nil, err
+	}
+	sub.EventTypes = make([]domain.WebhookEventType, len(eventTypes))
+	for i, t := range eventTypes {
+		sub.EventTypes[i] = domain.WebhookEventType(t)
+	}
+	return &sub, nil
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, url, secret string, eventTypes []domain.WebhookEventType) (*domain.WebhookSubscription, error) {
+	conn := r.db.Conn(ctx)
+
+	types := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		types[i] = string(t)
+	}
+
+	sub, err := scanSubscription(conn.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, secret, event_types)
+		VALUES ($1, $2, $3)
+		RETURNING `+subscriptionColumns,
+		url, secret, types))
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+	return sub, nil
+}
+
+// GetSubscription возвращает подписку вместе с секретом
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	conn := r.db.Conn(ctx)
+
+	sub, err := scanSubscription(conn.QueryRow(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+	if err != nil {
+		return nil, HandleNoRowsError(err)
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions ORDER BY created_at, id`)
+}
+
+// ActiveSubscriptionsFor возвращает активные подписки на событие eventType
+func (r *WebhookRepository) ActiveSubscriptionsFor(ctx context.Context, eventType domain.WebhookEventType) ([]domain.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, `
+		SELECT `+subscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE is_active AND $1 = ANY(event_types)
+		ORDER BY id
+	`, string(eventType))
+}
+
+func (r *WebhookRepository) listSubscriptions(ctx context.Context, query string, args ...interface{}) ([]domain.WebhookSubscription, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		subs = append(subs, *sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// DeleteSubscription удаляет подписку вместе с журналом доставок. false - подписки нет
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) (bool, error) {
+	conn := r.db.Conn(ctx)
+
+	tag, err := conn.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// CreateDelivery создает доставку. Для уже существующей доставки того же события
+// (повторная публикация из outbox) возвращает created == false
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, subscriptionID string, eventID *int64, eventSeq int,
+	eventType domain.WebhookEventType, payload []byte) (id int64, created bool, err error) {
+	conn := r.db.Conn(ctx)
+
+	err = conn.QueryRow(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_seq, event_type, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscription_id, event_id, event_seq) DO NOTHING
+		RETURNING id
+	`, subscriptionID, eventID, eventSeq, string(eventType), payload).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to insert webhook delivery: %w", err)
+	}
+	return id, true, nil
+}
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, created_at, delivered_at`
+
+func scanDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var eventType, status string
+	if err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &eventType, &d.Payload, &status, &d.CreatedAt, &d.DeliveredAt); err != nil {
+		return nil, err
+	}
+	d.EventType = domain.WebhookEventType(eventType)
+	d.Status = domain.WebhookDeliveryStatus(status)
+	d.Attempts = []domain.WebhookAttempt{}
+	return &d, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	conn := r.db.Conn(ctx)
+
+	d, err := scanDelivery(conn.QueryRow(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id))
+	if err != nil {
+		return nil, HandleNoRowsError(err)
+	}
+	return d, nil
+}
+
+func (r *WebhookRepository) SetDeliveryStatus(ctx context.Context, id int64, status domain.WebhookDeliveryStatus) error {
+	conn := r.db.Conn(ctx)
+
+	_, err := conn.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, delivered_at = CASE WHEN $2 = 'DELIVERED' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1
+	`, id, string(status))
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// AddAttempt записывает HTTP-попытку доставки в журнал
+func (r *WebhookRepository) AddAttempt(ctx context.Context, deliveryID int64, attempt domain.WebhookAttempt) error {
+	conn := r.db.Conn(ctx)
+
+	var statusCode *int
+	if attempt.StatusCode != 0 {
+		statusCode = &attempt.StatusCode
+	}
+	var errMsg *string
+	if attempt.Error != "" {
+		errMsg = &attempt.Error
+	}
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+	`, deliveryID, attempt.Attempt, statusCode, errMsg, attempt.DurationMs)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки подписки с попытками, от новых к старым
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error) {
+	conn := r.db.Conn(ctx)
+
+	rows, err := conn.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	byID := make(map[int64]int)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		byID[d.ID] = len(deliveries)
+		ids = append(ids, d.ID)
+		deliveries = append(deliveries, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return deliveries, nil
+	}
+
+	attemptRows, err := conn.Query(ctx, `
+		SELECT delivery_id, attempt, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook attempts: %w", err)
+	}
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var deliveryID int64
+		var a domain.WebhookAttempt
+		if err := attemptRows.Scan(&deliveryID, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMs, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook attempt: %w", err)
+		}
+		i := byID[deliveryID]
+		deliveries[i].Attempts = append(deliveries[i].Attempts, a)
+	}
+
+	return deliveries, attemptRows.Err()
+}
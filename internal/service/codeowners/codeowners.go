@@ -0,0 +1,175 @@
+package codeowners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+type CodeOwnersRepository interface {
+	Set(ctx context.Context, teamName, content string) (time.Time, error)
+	Get(ctx context.Context, teamName string) (string, time.Time, error)
+}
+
+type TeamRepository interface {
+	GetVersion(ctx context.Context, teamName string) (int64, error)
+	GetVersionForUpdate(ctx context.Context, teamName string) (int64, error)
+	BumpVersions(ctx context.Context, teamNames []string) error
+}
+
+// IdentityResolver сопоставляет логин GitHub пользователю сервиса
+type IdentityResolver interface {
+	Resolve(ctx context.Context, provider domain.VCSProvider, login string) (string, bool, error)
+}
+
+// CodeOwnersService хранит CODEOWNERS команд и определяет владельцев измененных файлов
+type CodeOwnersService struct {
+	repo       CodeOwnersRepository
+	teamRepo   TeamRepository
+	identities IdentityResolver
+	txManager  database.TransactionManagerInterface
+	lg         *slog.Logger
+}
+
+func NewCodeOwnersService(repo CodeOwnersRepository,
+	teamRepo TeamRepository,
+	identities IdentityResolver,
+	txManager database.TransactionManagerInterface,
+	lg *slog.Logger) *CodeOwnersService {
+	return &CodeOwnersService{
+		repo:       repo,
+		teamRepo:   teamRepo,
+		identities: identities,
+		txManager:  txManager,
+		lg:         lg,
+	}
+}
+
+// Set проверяет и сохраняет CODEOWNERS команды. CODEOWNERS - часть состояния команды, поэтому
+// загрузка проверяет версию команды (expectedTeamVersion из If-Match, nil - без проверки) и увеличивает ее
+func (s *CodeOwnersService) Set(ctx context.Context, teamName, content string, expectedTeamVersion []int64) (*domain.CodeOwners, error) {
+	rs, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedAt time.Time
+	var version int64
+	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
+		current, err := s.teamRepo.GetVersionForUpdate(txCtx, teamName)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrTeamNotFound
+			}
+			return fmt.Errorf("failed to lock team %s: %w", teamName, err)
+		}
+
+		if err := domain.CheckVersion(expectedTeamVersion, current); err != nil {
+			return err
+		}
+
+		if updatedAt, err = s.repo.Set(txCtx, teamName, content); err != nil {
+			return err
+		}
+		if err := s.teamRepo.BumpVersions(txCtx, []string{teamName}); err != nil {
+			return err
+		}
+		version = current + 1
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.lg.Info("CODEOWNERS uploaded", slog.String("team_name", teamName), slog.Int("rules", len(rs.rules)))
+
+	return &domain.CodeOwners{TeamName: teamName, Content: content, Rules: rs.Rules(), UpdatedAt: &updatedAt, Version: version}, nil
+}
+
+func (s *CodeOwnersService) Get(ctx context.Context, teamName string) (*domain.CodeOwners, error) {
+	content, updatedAt, err := s.repo.Get(ctx, teamName)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrCodeOwnersNotFound
+		}
+		return nil, fmt.Errorf("failed to get CODEOWNERS: %w", err)
+	}
+
+	rs, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.teamRepo.GetVersion(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team version: %w", err)
+	}
+	return &domain.CodeOwners{TeamName: teamName, Content: content, Rules: rs.Rules(), UpdatedAt: &updatedAt, Version: version}, nil
+}
+
+// OwnedFiles возвращает владельцев измененных файлов по CODEOWNERS команды: user_id -> сколько файлов
+// из files ему принадлежит. Владелец @login - логин GitHub из /admin/vcs/identities или user_id.
+// Команды GitHub (@org/team) и email пропускаются. Без CODEOWNERS возвращает пустой результат
+func (s *CodeOwnersService) OwnedFiles(ctx context.Context, teamName string, files []string) (map[string]int, error) {
+	owned := make(map[string]int)
+	if len(files) == 0 {
+		return owned, nil
+	}
+
+	content, _, err := s.repo.Get(ctx, teamName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return owned, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CODEOWNERS: %w", err)
+	}
+	rs, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string)
+	for _, file := range files {
+		// у одного файла владелец учитывается один раз, даже если указан несколькими логинами
+		fileOwners := make(map[string]struct{})
+		for _, owner := range rs.Owners(file) {
+			userID, ok := resolved[owner]
+			if !ok {
+				if userID, err = s.resolveOwner(ctx, owner); err != nil {
+					return nil, err
+				}
+				resolved[owner] = userID
+			}
+			if userID != "" {
+				fileOwners[userID] = struct{}{}
+			}
+		}
+		for userID := range fileOwners {
+			owned[userID]++
+		}
+	}
+	return owned, nil
+}
+
+// resolveOwner возвращает user_id владельца; пустая строка - владелец не пользователь сервиса
+func (s *CodeOwnersService) resolveOwner(ctx context.Context, owner string) (string, error) {
+	login, ok := strings.CutPrefix(owner, "@")
+	if !ok || strings.Contains(login, "/") {
+		return "", nil
+	}
+
+	userID, ok, err := s.identities.Resolve(ctx, domain.VCSGitHub, login)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return userID, nil
+	}
+	return login, nil
+}
@@ -0,0 +1,123 @@
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// Ruleset - разобранный CODEOWNERS. Правила проверяются снизу вверх, и действует последнее
+// совпавшее правило, как в GitHub: более частные правила пишутся ниже общих
+type Ruleset struct {
+	rules []rule
+}
+
+type rule struct {
+	domain.CodeOwnersRule
+	re *regexp.Regexp
+}
+
+// Parse разбирает CODEOWNERS: строки вида "<шаблон> <владелец>...", комментарии с #.
+// Правило без владельцев снимает владельцев с подходящих путей.
+// Отрицание (!) и диапазоны ([a-z]) GitHub в CODEOWNERS не поддерживает, такие строки - ошибка
+func Parse(content string) (*Ruleset, error) {
+	var rs Ruleset
+	for i, line := range strings.Split(content, "\n") {
+		if hash := strings.Index(line, "#"); hash >= 0 {
+			line = line[:hash]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+			return nil, fmt.Errorf("%w: line %d: unsupported pattern %q", domain.ErrInvalidCodeOwners, i+1, pattern)
+		}
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidCodeOwners, i+1, err)
+		}
+
+		rs.rules = append(rs.rules, rule{
+			CodeOwnersRule: domain.CodeOwnersRule{Line: i + 1, Pattern: pattern, Owners: fields[1:]},
+			re:             re,
+		})
+	}
+	return &rs, nil
+}
+
+// Rules возвращает правила в порядке файла
+func (rs *Ruleset) Rules() []domain.CodeOwnersRule {
+	rules := make([]domain.CodeOwnersRule, len(rs.rules))
+	for i, r := range rs.rules {
+		rules[i] = r.CodeOwnersRule
+		if rules[i].Owners == nil {
+			rules[i].Owners = []string{}
+		}
+	}
+	return rules
+}
+
+// Owners возвращает владельцев пути по последнему совпавшему правилу; nil - владельцев нет
+func (rs *Ruleset) Owners(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "./"), "/")
+	for i := len(rs.rules) - 1; i >= 0; i-- {
+		if rs.rules[i].re.MatchString(path) {
+			return rs.rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// compilePattern переводит шаблон в регулярное выражение по правилам gitignore:
+//   - шаблон с / в начале или в середине привязан к корню, без / - совпадает на любой глубине
+//   - * и ? не переходят через /, ** - любое число каталогов
+//   - шаблон с / в конце совпадает с содержимым каталога, шаблон без символов подстановки
+//     в последнем сегменте - с файлом или каталогом со всем содержимым
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	p = strings.TrimPrefix(p, "/")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+
+	lastSegment := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dirOnly:
+		b.WriteString("/.*")
+	case !strings.ContainsAny(lastSegment, "*?"):
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,210 @@
+package codeowners
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			pattern: "*",
+			match:   []string{"README.md", "src/main.go", "a/b/c/d.txt"},
+		},
+		{
+			// без / шаблон совпадает на любой глубине
+			pattern: "*.js",
+			match:   []string{"app.js", "src/app.js", "src/web/app.min.js"},
+			noMatch: []string{"app.jsx", "app.js.map"},
+		},
+		{
+			pattern: "Makefile",
+			match:   []string{"Makefile", "tools/Makefile", "Makefile/rules.mk"},
+			noMatch: []string{"Makefile.old", "GNUMakefile"},
+		},
+		{
+			// / в начале привязывает шаблон к корню
+			pattern: "/Makefile",
+			match:   []string{"Makefile"},
+			noMatch: []string{"tools/Makefile"},
+		},
+		{
+			// / в середине тоже привязывает к корню
+			pattern: "docs/*",
+			match:   []string{"docs/getting-started.md"},
+			noMatch: []string{"docs/build-app/troubleshooting.md", "src/docs/getting-started.md", "docs"},
+		},
+		{
+			// / только в конце не привязывает к корню
+			pattern: "apps/",
+			match:   []string{"apps/main.go", "apps/cli/main.go", "src/apps/main.go"},
+			noMatch: []string{"apps", "myapps/main.go"},
+		},
+		{
+			// / в конце - только содержимое каталога, а не файл с тем же именем
+			pattern: "/build/logs/",
+			match:   []string{"build/logs/app.log", "build/logs/2025/app.log"},
+			noMatch: []string{"build/logs", "src/build/logs/app.log", "build/logs2/app.log"},
+		},
+		{
+			// без символов подстановки - файл или каталог со всем содержимым
+			pattern: "/apps/github",
+			match:   []string{"apps/github", "apps/github/client.go", "apps/github/api/v3.go"},
+			noMatch: []string{"apps/github2", "apps/github.go", "src/apps/github"},
+		},
+		{
+			pattern: "**/logs",
+			match:   []string{"logs", "logs/app.log", "build/logs/app.log", "deeply/nested/logs/app.log"},
+			noMatch: []string{"build/logs2/app.log", "catalogs/app.log"},
+		},
+		{
+			pattern: "docs/**/*.md",
+			match:   []string{"docs/index.md", "docs/api/index.md", "docs/api/v1/users.md"},
+			noMatch: []string{"docs/index.txt", "src/docs/index.md"},
+		},
+		{
+			pattern: "/scripts/**",
+			match:   []string{"scripts/deploy.sh", "scripts/ci/lint.sh"},
+			noMatch: []string{"tools/scripts/deploy.sh"},
+		},
+		{
+			pattern: "a/**/b",
+			match:   []string{"a/b", "a/x/b", "a/x/y/b", "a/x/b/c.go"},
+			noMatch: []string{"a/bc", "x/a/b"},
+		},
+		{
+			// * и ? не переходят через /
+			pattern: "/src/*.go",
+			match:   []string{"src/main.go"},
+			noMatch: []string{"src/internal/main.go"},
+		},
+		{
+			pattern: "file?.txt",
+			match:   []string{"file1.txt", "dir/fileA.txt"},
+			noMatch: []string{"file.txt", "file10.txt", "file/.txt"},
+		},
+		{
+			// точка и другие символы регулярных выражений экранируются
+			pattern: "/go.mod",
+			match:   []string{"go.mod"},
+			noMatch: []string{"goxmod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := compilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compilePattern(%q) error = %v", tt.pattern, err)
+			}
+			for _, path := range tt.match {
+				if !re.MatchString(path) {
+					t.Errorf("%q should match %q (regexp %s)", tt.pattern, path, re)
+				}
+			}
+			for _, path := range tt.noMatch {
+				if re.MatchString(path) {
+					t.Errorf("%q should not match %q (regexp %s)", tt.pattern, path, re)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesetOwners(t *testing.T) {
+	const content = `# владельцы по умолчанию
+*                 @global-owner
+
+*.js              @js-owner @octocat  # фронтенд
+/docs/            @docs-owner
+/docs/internal/
+apps/             @apps-owner
+/apps/github      @github-owner
+`
+
+	rs, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "README.md", want: []string{"@global-owner"}},
+		{path: "src/app.js", want: []string{"@js-owner", "@octocat"}},
+		// действует последнее совпавшее правило, даже если предыдущее точнее по расширению
+		{path: "docs/app.js", want: []string{"@docs-owner"}},
+		{path: "docs/guide.md", want: []string{"@docs-owner"}},
+		// правило без владельцев снимает владельцев
+		{path: "docs/internal/runbook.md", want: nil},
+		{path: "docs/internal/tool.js", want: nil},
+		{path: "apps/cli/main.go", want: []string{"@apps-owner"}},
+		{path: "src/apps/main.go", want: []string{"@apps-owner"}},
+		{path: "apps/github/client.go", want: []string{"@github-owner"}},
+		// ./ и / в начале пути не влияют на результат
+		{path: "./apps/github/client.go", want: []string{"@github-owner"}},
+		{path: "/docs/guide.md", want: []string{"@docs-owner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rs.Owners(tt.path); !slices.Equal(got, tt.want) {
+				t.Errorf("Owners(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesetOwnersWithoutMatch(t *testing.T) {
+	rs, err := Parse("/docs/ @docs-owner\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := rs.Owners("src/main.go"); got != nil {
+		t.Errorf("Owners() = %q, want nil", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	rs, err := Parse("# comment\n\n*.go @go-owner # trailing\n/docs/\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []domain.CodeOwnersRule{
+		{Line: 3, Pattern: "*.go", Owners: []string{"@go-owner"}},
+		{Line: 4, Pattern: "/docs/", Owners: []string{}},
+	}
+	got := rs.Rules()
+	if len(got) != len(want) {
+		t.Fatalf("Rules() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Line != want[i].Line || got[i].Pattern != want[i].Pattern || !slices.Equal(got[i].Owners, want[i].Owners) {
+			t.Errorf("rule %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"negation":    "*.go @a\n!vendor/ @b\n",
+		"range":       "[ab].go @a\n",
+		"root only":   "/ @a\n",
+		"double root": "// @a\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(content); !errors.Is(err, domain.ErrInvalidCodeOwners) {
+				t.Fatalf("Parse() error = %v, want %v", err, domain.ErrInvalidCodeOwners)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// GetFairness сравнивает долю назначений каждого активного участника команды
+// с равномерной долей 1/n за окно
+func (s *StatsService) GetFairness(ctx context.Context, filter domain.StatsFilter) (*domain.FairnessReport, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	userStats, err := s.statsRepo.GetUserAssignmentStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// группируем активных пользователей по командам
+	byTeam := make(map[string][]domain.UserAssignmentStats)
+	for _, us := range userStats {
+		if !us.IsActive {
+			continue
+		}
+		byTeam[us.TeamName] = append(byTeam[us.TeamName], us)
+	}
+
+	report := &domain.FairnessReport{
+		From:     filter.From,
+		To:       filter.To,
+		TeamName: filter.TeamName,
+		Teams:    make([]domain.TeamFairness, 0, len(byTeam)),
+	}
+
+	for teamName, members := range byTeam {
+		report.Teams = append(report.Teams, teamFairness(teamName, members))
+	}
+	sort.Slice(report.Teams, func(i, j int) bool {
+		return report.Teams[i].TeamName < report.Teams[j].TeamName
+	})
+
+	s.logger.Info("fairness report built", "teams", len(report.Teams))
+
+	return report, nil
+}
+
+func teamFairness(teamName string, members []domain.UserAssignmentStats) domain.TeamFairness {
+	n := len(members)
+	counts := make([]int64, n)
+
+	var total, maxCount, minCount int64
+	for i, m := range members {
+		counts[i] = m.PRCount
+		total += m.PRCount
+		if i == 0 || m.PRCount > maxCount {
+			maxCount = m.PRCount
+		}
+		if i == 0 || m.PRCount < minCount {
+			minCount = m.PRCount
+		}
+	}
+
+	expected := 1 / float64(n)
+	result := domain.TeamFairness{
+		TeamName:         teamName,
+		ActiveMembers:    n,
+		TotalAssignments: total,
+		ExpectedShare:    expected,
+		Gini:             gini(counts),
+		MostLoaded:       []string{},
+		LeastLoaded:      []string{},
+		Members:          make([]domain.MemberFairness, 0, n),
+	}
+
+	for _, m := range members {
+		share := 0.0
+		if total > 0 {
+			share = float64(m.PRCount) / float64(total)
+		}
+		result.Members = append(result.Members, domain.MemberFairness{
+			UserID:      m.UserID,
+			Username:    m.Username,
+			Assignments: m.PRCount,
+			Share:       share,
+			Deviation:   share - expected,
+		})
+
+		// при нулевой нагрузке у всех выделять некого
+		if total == 0 {
+			continue
+		}
+		if m.PRCount == maxCount {
+			result.MostLoaded = append(result.MostLoaded, m.UserID)
+		}
+		if m.PRCount == minCount {
+			result.LeastLoaded = append(result.LeastLoaded, m.UserID)
+		}
+	}
+
+	return result
+}
+
+// gini - коэффициент Джини: 0 при равном распределении, ближе к 1 при концентрации на одном участнике
+func gini(counts []int64) float64 {
+	n := len(counts)
+	if n == 0 {
+		return 0
+	}
+
+	sorted := make([]int64, n)
+	copy(sorted, counts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum, weighted float64
+	for i, c := range sorted {
+		sum += float64(c)
+		weighted += float64(i+1) * float64(c)
+	}
+	if sum == 0 {
+		return 0
+	}
+
+	return 2*weighted/(float64(n)*sum) - float64(n+1)/float64(n)
+}
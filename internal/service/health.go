@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// таймаут проверки доступности БД
+const healthPingTimeout = 2 * time.Second
+
+type DBPool interface {
+	Ping(ctx context.Context) error
+	Stat() *pgxpool.Stat
+}
+
+type HealthService struct {
+	db     DBPool
+	logger *slog.Logger
+}
+
+func NewHealthService(db DBPool, logger *slog.Logger) *HealthService {
+	return &HealthService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *HealthService) Check(ctx context.Context) *domain.HealthResponse {
+	response := &domain.HealthResponse{
+		Status: domain.HealthStatusOK,
+		Database: domain.DatabaseHealth{
+			Status: domain.HealthStatusOK,
+		},
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+
+	// /health доступен без авторизации, а текст ошибки драйвера может содержать хост, пользователя и имя БД,
+	// поэтому он только логируется
+	if err := s.db.Ping(pingCtx); err != nil {
+		s.logger.Warn("database health check failed", "error", err)
+		response.Status = domain.HealthStatusUnavailable
+		response.Database.Status = domain.HealthStatusUnavailable
+	}
+
+	stat := s.db.Stat()
+	response.Database.Pool = domain.DBPoolStats{
+		MaxConns:                stat.MaxConns(),
+		TotalConns:              stat.TotalConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		AcquireCount:            stat.AcquireCount(),
+		AcquireDurationMs:       stat.AcquireDuration().Milliseconds(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+	}
+
+	return response
+}
@@ -0,0 +1,394 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+	"ynastt/avito_test_task_backend_2025/pkg/retry"
+)
+
+// ErrJobCancelled - причина отмены контекста обработчика, если задачу отменили через admin API
+var ErrJobCancelled = errors.New("job cancelled")
+
+// errDrainTimeout - причина отмены контекста обработчиков, не успевших завершиться при остановке
+var errDrainTimeout = errors.New("drain timeout expired")
+
+type JobRepository interface {
+	Enqueue(ctx context.Context, kind string, payload []byte, maxAttempts int, runAt *time.Time) (*domain.Job, error)
+	InsertClaimed(ctx context.Context, kind string, payload []byte, maxAttempts int, token string, lease time.Duration) (*domain.Job, error)
+	Claim(ctx context.Context, kinds []string, token string, lease time.Duration) (*domain.Job, error)
+	FailExpired(ctx context.Context, kinds []string) ([]string, error)
+	Heartbeat(ctx context.Context, jobID, token string, lease time.Duration) (bool, error)
+	Complete(ctx context.Context, jobID, token string) error
+	Fail(ctx context.Context, jobID, token, errMsg string, retryAt *time.Time) error
+	Release(ctx context.Context, jobID, token string) error
+	Get(ctx context.Context, jobID string) (*domain.Job, error)
+	List(ctx context.Context, filter domain.JobsFilter) ([]domain.Job, error)
+	Cancel(ctx context.Context, jobID string) (bool, error)
+	Retry(ctx context.Context, jobID string) (bool, error)
+}
+
+// Handler выполняет задачу своего вида. Ошибка планирует повторную попытку,
+// после MaxAttempts попыток задача уходит в DEAD.
+// ctx отменяется, если задачу отменили (причина ErrJobCancelled) или остановка сервера не дождалась обработчика
+type Handler func(ctx context.Context, job *domain.Job) error
+
+type Config struct {
+	// число параллельно выполняемых задач
+	Workers int
+	// как часто свободный исполнитель проверяет очередь
+	PollInterval time.Duration
+	// попыток на задачу по умолчанию
+	MaxAttempts int
+	// задержка перед второй попыткой, дальше удваивается до MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// аренда задачи; исполнитель продлевает ее, пока задача выполняется
+	Lease time.Duration
+	// сколько при остановке ждать выполняющиеся задачи
+	DrainTimeout time.Duration
+}
+
+// JobService - общая очередь фоновых задач в Postgres
+type JobService struct {
+	repo      JobRepository
+	txManager database.TransactionManagerInterface
+	cfg       Config
+	handlers  map[string]Handler
+	wakeup    chan struct{}
+	lg        *slog.Logger
+}
+
+func NewJobService(repo JobRepository,
+	txManager database.TransactionManagerInterface,
+	cfg Config,
+	lg *slog.Logger) *JobService {
+	return &JobService{
+		repo:      repo,
+		txManager: txManager,
+		cfg:       cfg,
+		handlers:  make(map[string]Handler),
+		wakeup:    make(chan struct{}, 1),
+		lg:        lg,
+	}
+}
+
+// Register задает обработчик задач вида kind. Вызывается до Run
+func (s *JobService) Register(kind string, handler Handler) {
+	s.handlers[kind] = handler
+}
+
+// Enqueue ставит задачу в очередь. Внутри транзакции задача появится в очереди только после коммита,
+// поэтому после него стоит вызвать Wake
+func (s *JobService) Enqueue(ctx context.Context, kind string, payload interface{}) (*domain.Job, error) {
+	data, err := marshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.repo.Enqueue(ctx, kind, data, s.cfg.MaxAttempts, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.lg.Info("job enqueued", slog.String("job_id", job.ID), slog.String("kind", kind))
+
+	s.Wake()
+	return job, nil
+}
+
+// Wake будит свободного исполнителя, не дожидаясь очередного опроса
+func (s *JobService) Wake() {
+	select {
+	case s.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// RunNow создает задачу уже захваченной текущим процессом и сразу выполняет ее, без исполнителей Run.
+// init вызывается в той же транзакции, что и создание задачи. Используется CLI;
+// если процесс прервется, задачу после истечения аренды подхватит сервер
+func (s *JobService) RunNow(ctx context.Context, kind string, payload interface{}, init func(ctx context.Context, job *domain.Job) error) (*domain.Job, error) {
+	handler, ok := s.handlers[kind]
+	if !ok {
+		return nil, fmt.Errorf("no handler registered for job kind %q", kind)
+	}
+
+	data, err := marshalPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	token := newToken()
+	var job *domain.Job
+	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
+		job, err = s.repo.InsertClaimed(txCtx, kind, data, s.cfg.MaxAttempts, token, s.cfg.Lease)
+		if err != nil {
+			return err
+		}
+		return init(txCtx, job)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.execute(ctx, ctx, job, token, handler)
+	return s.Get(ctx, job.ID)
+}
+
+// Run запускает cfg.Workers исполнителей и блокируется до отмены ctx.
+// После отмены новые задачи не захватываются, а выполняющиеся получают cfg.DrainTimeout на завершение;
+// не успевшие возвращаются в очередь без потери попытки
+func (s *JobService) Run(ctx context.Context) {
+	kinds := make([]string, 0, len(s.handlers))
+	for kind := range s.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	// контекст обработчиков не зависит от ctx и отменяется только по истечении DrainTimeout
+	handlerCtx, stopHandlers := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer stopHandlers(nil)
+	go func() {
+		<-ctx.Done()
+		timer := time.NewTimer(s.cfg.DrainTimeout)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			stopHandlers(errDrainTimeout)
+		case <-handlerCtx.Done():
+		}
+	}()
+
+	s.lg.Info("job workers started", slog.Int("workers", s.cfg.Workers), slog.Any("kinds", kinds))
+
+	var wg sync.WaitGroup
+	for range s.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, handlerCtx, kinds)
+		}()
+	}
+	wg.Wait()
+
+	s.lg.Info("job workers stopped")
+}
+
+func (s *JobService) work(ctx, handlerCtx context.Context, kinds []string) {
+	for ctx.Err() == nil {
+		s.failExpired(ctx, kinds)
+
+		token := newToken()
+		job, err := s.repo.Claim(ctx, kinds, token, s.cfg.Lease)
+		if err != nil && ctx.Err() == nil {
+			s.lg.Error("failed to claim job", slog.Any("error", err))
+		}
+
+		if job != nil {
+			s.execute(ctx, handlerCtx, job, token, s.handlers[job.Kind])
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.wakeup:
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+// failExpired переводит в DEAD задачи с истекшей арендой, исчерпавшие попытки.
+// Иначе задача, на которой исполнитель падает или зависает, возвращалась бы в работу бесконечно
+func (s *JobService) failExpired(ctx context.Context, kinds []string) {
+	ids, err := s.repo.FailExpired(ctx, kinds)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.lg.Error("failed to check expired jobs", slog.Any("error", err))
+		}
+		return
+	}
+	for _, id := range ids {
+		s.lg.Error("job lease expired, attempts exhausted", slog.String("job_id", id))
+	}
+}
+
+// execute выполняет захваченную задачу, продлевая аренду, и записывает результат.
+// Служебные запросы выполняются без отмены, чтобы результат сохранился и при остановке
+func (s *JobService) execute(ctx, handlerCtx context.Context, job *domain.Job, token string, handler Handler) {
+	log := s.lg.With(
+		slog.String("job_id", job.ID),
+		slog.String("kind", job.Kind),
+		slog.Int("attempt", job.Attempts))
+	dbCtx := context.WithoutCancel(ctx)
+
+	runCtx, cancel := context.WithCancelCause(handlerCtx)
+	defer cancel(nil)
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		s.heartbeat(runCtx, dbCtx, job.ID, token, cancel, log)
+	}()
+
+	log.Info("job started")
+	err := handler(runCtx, job)
+	cause := context.Cause(runCtx)
+	cancel(nil)
+	<-heartbeatDone
+
+	switch {
+	case errors.Is(cause, ErrJobCancelled):
+		log.Info("job cancelled")
+	case err == nil:
+		if err := s.repo.Complete(dbCtx, job.ID, token); err != nil {
+			log.Error("failed to mark job succeeded", slog.Any("error", err))
+			return
+		}
+		log.Info("job succeeded")
+	case errors.Is(cause, errDrainTimeout):
+		if err := s.repo.Release(dbCtx, job.ID, token); err != nil {
+			log.Error("failed to release job", slog.Any("error", err))
+			return
+		}
+		log.Warn("job interrupted by shutdown, returned to queue")
+	case job.Attempts >= job.MaxAttempts:
+		if err := s.repo.Fail(dbCtx, job.ID, token, err.Error(), nil); err != nil {
+			log.Error("failed to move job to dead letter", slog.Any("error", err))
+			return
+		}
+		log.Error("job failed, attempts exhausted", slog.Any("error", err))
+	default:
+		retryAt := time.Now().Add(retry.Backoff(job.Attempts, s.cfg.RetryBackoff, s.cfg.MaxRetryBackoff))
+		if err := s.repo.Fail(dbCtx, job.ID, token, err.Error(), &retryAt); err != nil {
+			log.Error("failed to schedule job retry", slog.Any("error", err))
+			return
+		}
+		log.Warn("job failed, retry scheduled", slog.Time("retry_at", retryAt), slog.Any("error", err))
+	}
+}
+
+// heartbeat продлевает аренду, пока задача выполняется.
+// Если задачу отменили или перехватили, отменяет контекст обработчика
+func (s *JobService) heartbeat(runCtx, dbCtx context.Context, jobID, token string, cancel context.CancelCauseFunc, log *slog.Logger) {
+	ticker := time.NewTicker(s.cfg.Lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-runCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		owned, err := s.repo.Heartbeat(dbCtx, jobID, token, s.cfg.Lease)
+		if err != nil {
+			log.Warn("failed to extend job lease", slog.Any("error", err))
+			continue
+		}
+		if !owned {
+			cancel(ErrJobCancelled)
+			return
+		}
+	}
+}
+
+func (s *JobService) Get(ctx context.Context, jobID string) (*domain.Job, error) {
+	job, err := s.repo.Get(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *JobService) List(ctx context.Context, filter domain.JobsFilter) (*domain.JobsResponse, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, domain.ErrInvalidInput
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = domain.DefaultPageLimit
+	case filter.Limit > domain.MaxPageLimit:
+		filter.Limit = domain.MaxPageLimit
+	}
+
+	jobs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []domain.Job{}
+	}
+	return &domain.JobsResponse{Jobs: jobs}, nil
+}
+
+// Cancel отменяет задачу. Выполняющийся обработчик узнает об отмене при следующем продлении аренды
+func (s *JobService) Cancel(ctx context.Context, jobID string) (*domain.Job, error) {
+	cancelled, err := s.repo.Cancel(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, domain.ErrJobNotCancellable
+	}
+
+	s.lg.Info("job cancelled by admin", slog.String("job_id", jobID))
+	return job, nil
+}
+
+// Retry возвращает в очередь задачу из DEAD или CANCELLED
+func (s *JobService) Retry(ctx context.Context, jobID string) (*domain.Job, error) {
+	retried, err := s.repo.Retry(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !retried {
+		return nil, domain.ErrJobNotRetryable
+	}
+
+	s.lg.Info("job retried by admin", slog.String("job_id", jobID))
+	s.Wake()
+	return job, nil
+}
+
+func marshalPayload(payload interface{}) ([]byte, error) {
+	if payload == nil {
+		return []byte("{}"), nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return data, nil
+}
+
+// newToken - токен захвата задачи, уникальный для каждой попытки
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
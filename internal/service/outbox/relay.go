@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+	"ynastt/avito_test_task_backend_2025/pkg/retry"
+)
+
+// как часто удалять опубликованные события старше Config.Retention
+const cleanupInterval = time.Hour
+
+type OutboxRepository interface {
+	LockPending(ctx context.Context, limit int) ([]domain.OutboxEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	MarkFailed(ctx context.Context, id int64, errMsg string, nextAttemptAt time.Time) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Sink - получатель событий. Publish должен быть идемпотентным по event.ID:
+// событие может прийти повторно, если релей упал после отправки или другой получатель вернул ошибку
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+}
+
+type Config struct {
+	// как часто проверять новые события, если очередь пуста
+	PollInterval time.Duration
+	// сколько событий отправлять за одну транзакцию
+	BatchSize int
+	// таймаут отправки одного события одному получателю
+	PublishTimeout time.Duration
+	// задержка перед повторной отправкой, удваивается до MaxRetryBackoff
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// сколько хранить опубликованные события
+	Retention time.Duration
+}
+
+// Relay публикует события из outbox_events во все получатели не менее одного раза
+type Relay struct {
+	repo      OutboxRepository
+	txManager database.TransactionManagerInterface
+	sinks     []Sink
+	cfg       Config
+	lg        *slog.Logger
+}
+
+func NewRelay(repo OutboxRepository,
+	txManager database.TransactionManagerInterface,
+	sinks []Sink,
+	cfg Config,
+	lg *slog.Logger) *Relay {
+	return &Relay{
+		repo:      repo,
+		txManager: txManager,
+		sinks:     sinks,
+		cfg:       cfg,
+		lg:        lg,
+	}
+}
+
+// Run публикует события, пока ctx не отменен. Начатая пачка дописывается до конца
+func (r *Relay) Run(ctx context.Context) {
+	names := make([]string, len(r.sinks))
+	for i, s := range r.sinks {
+		names[i] = s.Name()
+	}
+	r.lg.Info("outbox relay started", slog.Any("sinks", names))
+
+	lastCleanup := time.Time{}
+	for ctx.Err() == nil {
+		if time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		n, err := r.publishBatch(context.WithoutCancel(ctx))
+		if err != nil {
+			r.lg.Error("failed to publish outbox events", slog.Any("error", err))
+		}
+
+		// полная пачка - в очереди, скорее всего, есть еще события
+		if err == nil && n == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+
+	r.lg.Info("outbox relay stopped")
+}
+
+// publishBatch отправляет пачку событий в транзакции, которая держит их блокировки до записи результата
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	var count int
+	err := r.txManager.Do(ctx, func(txCtx context.Context) error {
+		events, err := r.repo.LockPending(txCtx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		count = len(events)
+
+		published := make([]int64, 0, len(events))
+		for _, event := range events {
+			if err := r.publish(txCtx, event); err != nil {
+				nextAttemptAt := time.Now().Add(retry.Backoff(event.Attempts+1, r.cfg.RetryBackoff, r.cfg.MaxRetryBackoff))
+				r.lg.Warn("failed to publish outbox event, will retry",
+					slog.Int64("event_id", event.ID),
+					slog.String("type", string(event.Type)),
+					slog.Time("next_attempt_at", nextAttemptAt),
+					slog.Any("error", err))
+				if err := r.repo.MarkFailed(txCtx, event.ID, err.Error(), nextAttemptAt); err != nil {
+					return err
+				}
+				continue
+			}
+			published = append(published, event.ID)
+		}
+
+		return r.repo.MarkPublished(txCtx, published)
+	})
+	return count, err
+}
+
+// publish отправляет событие во все получатели. Ошибка любого из них ведет к повторной
+// отправке во все, поэтому получатели должны дедуплицировать события
+func (r *Relay) publish(ctx context.Context, event domain.OutboxEvent) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+		err := sink.Publish(sinkCtx, event)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.repo.DeletePublishedBefore(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			r.lg.Error("failed to clean up outbox", slog.Any("error", err))
+		}
+		return
+	}
+	if deleted > 0 {
+		r.lg.Info("published outbox events cleaned up", slog.Int64("deleted", deleted))
+	}
+}
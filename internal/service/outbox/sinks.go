@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const SinkLog = "log"
+
+// LogSink пишет события в лог сервиса
+type LogSink struct {
+	lg *slog.Logger
+}
+
+func NewLogSink(lg *slog.Logger) *LogSink {
+	return &LogSink{lg: lg}
+}
+
+func (s *LogSink) Name() string {
+	return SinkLog
+}
+
+func (s *LogSink) Publish(_ context.Context, event domain.OutboxEvent) error {
+	s.lg.Info("domain event",
+		slog.Int64("event_id", event.ID),
+		slog.String("type", string(event.Type)),
+		slog.String("aggregate_id", event.AggregateID),
+		slog.String("payload", string(event.Payload)))
+	return nil
+}
+
+// NewSinks выбирает из available получателей с именами из конфигурации
+func NewSinks(names []string, available ...Sink) ([]Sink, error) {
+	byName := make(map[string]Sink, len(available))
+	for _, sink := range available {
+		byName[sink.Name()] = sink
+	}
+
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		sink, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
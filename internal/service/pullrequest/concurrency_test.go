@@ -0,0 +1,422 @@
+package pullrequest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+	pr "ynastt/avito_test_task_backend_2025/internal/service/pullrequest"
+	"ynastt/avito_test_task_backend_2025/internal/service/user"
+	"ynastt/avito_test_task_backend_2025/migrations"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// Тесты гоняют переназначение, merge и деактивацию параллельно на настоящей БД:
+// без блокировок строк PR и пользователей они дают дубли ревьюеров и изменения MERGED PR.
+// Нужна пустая или тестовая БД Postgres в TEST_DATABASE_URL, миграции применяются автоматически
+
+const (
+	concurrencyRounds = 20
+	concurrentCalls   = 8
+)
+
+type nopWriteback struct{}
+
+func (nopWriteback) Schedule(context.Context, domain.VCSWriteback) error { return nil }
+func (nopWriteback) Wake()                                               {}
+
+type nopCodeOwners struct{}
+
+func (nopCodeOwners) OwnedFiles(context.Context, string, []string) (map[string]int, error) {
+	return nil, nil
+}
+
+type testEnv struct {
+	pool     *pgxpool.Pool
+	teams    *repository.TeamRepository
+	users    *repository.UserRepository
+	prs      *pr.PullRequestService
+	userSvc  *user.UserService
+	sequence int
+}
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrateOnce.Do(func() {
+		migrateErr = migrateUp(pool)
+	})
+	if migrateErr != nil {
+		t.Fatalf("failed to migrate: %v", migrateErr)
+	}
+
+	db := database.NewDB(pool)
+	txManager, err := database.NewTransactionManager(pool)
+	if err != nil {
+		t.Fatalf("failed to create transaction manager: %v", err)
+	}
+
+	lg := slog.New(slog.NewTextHandler(io.Discard, nil))
+	teamRepo := repository.NewTeamRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	prRepo := repository.NewPullRequestRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+
+	return &testEnv{
+		pool:  pool,
+		teams: teamRepo,
+		users: userRepo,
+		prs: pr.NewPullRequestService(prRepo, userRepo, outboxRepo, nopWriteback{}, nopCodeOwners{}, txManager,
+			2, domain.ReviewerStrategyRandom, time.Hour, lg),
+		userSvc: user.NewUserService(userRepo, prRepo, teamRepo, outboxRepo, txManager, lg),
+	}
+}
+
+func migrateUp(pool *pgxpool.Pool) error {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return err
+	}
+	driver, err := pgxmigrate.WithInstance(stdlib.OpenDBFromPool(pool), &pgxmigrate.Config{})
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "pgx5", driver)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// newPR создает команду из автора и четырех участников и PR этого автора с двумя ревьюерами,
+// так что на замену остается ровно два кандидата
+func (e *testEnv) newPR(t *testing.T) *domain.PullRequest {
+	t.Helper()
+	ctx := context.Background()
+
+	e.sequence++
+	prefix := fmt.Sprintf("cc-%d-%d", time.Now().UnixNano(), e.sequence)
+	teamName := prefix + "-team"
+	if err := e.teams.CreateTeam(ctx, teamName); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+
+	authorID := prefix + "-author"
+	for _, id := range []string{authorID, prefix + "-u1", prefix + "-u2", prefix + "-u3", prefix + "-u4"} {
+		member := domain.TeamMember{UserID: id, Username: id, IsActive: true}
+		if _, err := e.users.Upsert(ctx, member, teamName); err != nil {
+			t.Fatalf("failed to create user %s: %v", id, err)
+		}
+	}
+
+	created, err := e.prs.CreatePullRequest(ctx, domain.CreatePRRequest{
+		ID:       prefix + "-pr",
+		Name:     "concurrency",
+		AuthorID: authorID,
+	})
+	if err != nil {
+		t.Fatalf("failed to create PR: %v", err)
+	}
+	if len(created.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", created.AssignedReviewers)
+	}
+	return created
+}
+
+// checkReviewers проверяет, что среди ревьюеров нет дублей, автора и неактивных пользователей
+func (e *testEnv) checkReviewers(t *testing.T, got *domain.PullRequest) {
+	t.Helper()
+
+	seen := make(map[string]bool, len(got.AssignedReviewers))
+	for _, id := range got.AssignedReviewers {
+		if seen[id] {
+			t.Fatalf("PR %s has duplicate reviewer %s: %v", got.ID, id, got.AssignedReviewers)
+		}
+		seen[id] = true
+
+		if id == got.AuthorID {
+			t.Fatalf("PR %s has its author as reviewer: %v", got.ID, got.AssignedReviewers)
+		}
+
+		u, err := e.users.GetByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("failed to get reviewer %s: %v", id, err)
+		}
+		if !u.IsActive {
+			t.Fatalf("PR %s has inactive reviewer %s", got.ID, id)
+		}
+	}
+}
+
+func (e *testEnv) getPR(t *testing.T, prID string) *domain.PullRequest {
+	t.Helper()
+
+	details, err := e.prs.GetPullRequest(context.Background(), prID)
+	if err != nil {
+		t.Fatalf("failed to get PR: %v", err)
+	}
+	got := &domain.PullRequest{ID: details.ID, AuthorID: details.AuthorID, Status: details.Status}
+	for _, r := range details.AssignedReviewers {
+		got.AssignedReviewers = append(got.AssignedReviewers, r.UserID)
+	}
+	return got
+}
+
+func (e *testEnv) countReassignments(t *testing.T, prID string) int {
+	t.Helper()
+
+	var count int
+	err := e.pool.QueryRow(context.Background(),
+		`SELECT COUNT(*) FROM pr_reassignments WHERE pull_request_id = $1`, prID).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to count reassignments: %v", err)
+	}
+	return count
+}
+
+// run запускает fns одновременно и ждет их завершения
+func run(fns ...func()) {
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for _, fn := range fns {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			fn()
+		}()
+	}
+	start.Done()
+	done.Wait()
+}
+
+func TestConcurrentReassign(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	for round := 0; round < concurrencyRounds; round++ {
+		created := env.newPR(t)
+
+		// половина вызовов заменяет первого ревьюера, половина - второго
+		updated := make([]*domain.PullRequest, 2*concurrentCalls)
+		errs := make([]error, 2*concurrentCalls)
+		fns := make([]func(), 0, 2*concurrentCalls)
+		for i := range updated {
+			oldReviewerID := created.AssignedReviewers[i%2]
+			fns = append(fns, func() {
+				updated[i], _, errs[i] = env.prs.ReassignReviewer(ctx, created.ID, oldReviewerID, nil)
+			})
+		}
+		run(fns...)
+
+		successes := 0
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				env.checkReviewers(t, updated[i])
+				successes++
+			case errors.Is(err, domain.ErrNotAssigned), errors.Is(err, domain.ErrNoCandidate):
+			default:
+				t.Fatalf("unexpected reassign error: %v", err)
+			}
+		}
+
+		got := env.getPR(t, created.ID)
+		env.checkReviewers(t, got)
+		if len(got.AssignedReviewers) != 2 {
+			t.Fatalf("expected 2 reviewers after reassigns, got %v", got.AssignedReviewers)
+		}
+		if successes == 0 {
+			t.Fatalf("no reassign succeeded")
+		}
+		if n := env.countReassignments(t, created.ID); n != successes {
+			t.Fatalf("expected %d reassignment records, got %d", successes, n)
+		}
+	}
+}
+
+func TestConcurrentReassignAndMerge(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	for round := 0; round < concurrencyRounds; round++ {
+		created := env.newPR(t)
+
+		var reassigned *domain.PullRequest
+		var reassignErr, mergeErr error
+		run(
+			func() {
+				reassigned, _, reassignErr = env.prs.ReassignReviewer(ctx, created.ID, created.AssignedReviewers[0], nil)
+			},
+			func() {
+				_, mergeErr = env.prs.MergePullRequest(ctx, created.ID, nil)
+			},
+		)
+		if mergeErr != nil {
+			t.Fatalf("merge failed: %v", mergeErr)
+		}
+
+		got := env.getPR(t, created.ID)
+		if got.Status != domain.PRStatusMerged {
+			t.Fatalf("expected MERGED, got %s", got.Status)
+		}
+
+		// переназначение либо успело до merge, либо отклонено - состав ревьюеров MERGED PR
+		// совпадает с состоянием на момент merge
+		want := created.AssignedReviewers
+		switch {
+		case reassignErr == nil:
+			want = reassigned.AssignedReviewers
+		case errors.Is(reassignErr, domain.ErrPRMerged):
+		default:
+			t.Fatalf("unexpected reassign error: %v", reassignErr)
+		}
+		if !slices.Equal(got.AssignedReviewers, want) {
+			t.Fatalf("reviewers of merged PR changed: want %v, got %v", want, got.AssignedReviewers)
+		}
+
+		// после merge переназначение всегда отклоняется
+		if _, _, err := env.prs.ReassignReviewer(ctx, created.ID, got.AssignedReviewers[0], nil); !errors.Is(err, domain.ErrPRMerged) {
+			t.Fatalf("expected ErrPRMerged after merge, got %v", err)
+		}
+		if after := env.getPR(t, created.ID); !slices.Equal(after.AssignedReviewers, want) {
+			t.Fatalf("reviewers of merged PR changed: want %v, got %v", want, after.AssignedReviewers)
+		}
+	}
+}
+
+func TestConcurrentReassignAndDeactivation(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	t.Run("other reviewer", func(t *testing.T) {
+		for round := 0; round < concurrencyRounds; round++ {
+			created := env.newPR(t)
+			reassignedID, deactivatedID := created.AssignedReviewers[0], created.AssignedReviewers[1]
+
+			var reassignErr, deactivateErr error
+			run(
+				func() {
+					_, _, reassignErr = env.prs.ReassignReviewer(ctx, created.ID, reassignedID, nil)
+				},
+				func() {
+					_, deactivateErr = env.userSvc.SetIsActive(ctx, deactivatedID, false, nil)
+				},
+			)
+			if reassignErr != nil {
+				t.Fatalf("reassign failed: %v", reassignErr)
+			}
+			if deactivateErr != nil {
+				t.Fatalf("deactivation failed: %v", deactivateErr)
+			}
+
+			got := env.getPR(t, created.ID)
+			env.checkReviewers(t, got)
+			if len(got.AssignedReviewers) != 2 {
+				t.Fatalf("expected 2 reviewers, got %v", got.AssignedReviewers)
+			}
+		}
+	})
+
+	t.Run("same reviewer", func(t *testing.T) {
+		for round := 0; round < concurrencyRounds; round++ {
+			created := env.newPR(t)
+			reviewerID := created.AssignedReviewers[0]
+
+			var reassignErr, deactivateErr error
+			run(
+				func() {
+					_, _, reassignErr = env.prs.ReassignReviewer(ctx, created.ID, reviewerID, nil)
+				},
+				func() {
+					_, deactivateErr = env.userSvc.SetIsActive(ctx, reviewerID, false, nil)
+				},
+			)
+			// деактивация первой уже заменила ревьюера
+			if reassignErr != nil && !errors.Is(reassignErr, domain.ErrNotAssigned) {
+				t.Fatalf("unexpected reassign error: %v", reassignErr)
+			}
+			if deactivateErr != nil {
+				t.Fatalf("deactivation failed: %v", deactivateErr)
+			}
+
+			got := env.getPR(t, created.ID)
+			env.checkReviewers(t, got)
+			if len(got.AssignedReviewers) != 2 {
+				t.Fatalf("expected 2 reviewers, got %v", got.AssignedReviewers)
+			}
+		}
+	})
+}
+
+// Кандидат, строку которого держит другая транзакция (например, смена навыков), остается кандидатом:
+// пропускаются только пользователи, которых прямо сейчас деактивируют
+func TestReassignWhileCandidateRowIsLocked(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	created := env.newPR(t)
+	prefix := strings.TrimSuffix(created.AuthorID, "-author")
+	var spare []string
+	for _, id := range []string{prefix + "-u1", prefix + "-u2", prefix + "-u3", prefix + "-u4"} {
+		if !slices.Contains(created.AssignedReviewers, id) {
+			spare = append(spare, id)
+		}
+	}
+
+	tx, err := env.pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `UPDATE users SET skills = '{go}' WHERE user_id = ANY($1)`, spare); err != nil {
+		t.Fatalf("failed to lock candidates: %v", err)
+	}
+
+	reassignCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	updated, newReviewerID, err := env.prs.ReassignReviewer(reassignCtx, created.ID, created.AssignedReviewers[0], nil)
+	if err != nil {
+		t.Fatalf("reassign failed while candidate rows are locked: %v", err)
+	}
+	if !slices.Contains(spare, newReviewerID) {
+		t.Fatalf("new reviewer %s is not one of the spare members %v", newReviewerID, spare)
+	}
+	env.checkReviewers(t, updated)
+}
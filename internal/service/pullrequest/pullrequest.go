@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"ynastt/avito_test_task_backend_2025/internal/domain"
@@ -14,37 +16,76 @@ import (
 )
 
 type PullRequestRepository interface {
-	CreatePullRequest(ctx context.Context, pr domain.CreatePRRequest) (time.Time, error)
+	CreatePullRequest(ctx context.Context, pr domain.CreatePRRequest, reviewerIDs []string) (*domain.PullRequest, error)
 	Exists(ctx context.Context, prID string) (bool, error)
-	AssignReviewer(ctx context.Context, reviewerID, prID string) error
-	RemoveReviewer(ctx context.Context, reviewerID, prID string) error
 	GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error)
+	GetPullRequestByIDForUpdate(ctx context.Context, prID string) (*domain.PullRequest, error)
 	GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
 	MergePullRequest(ctx context.Context, prID string) error
 	IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error)
+	AddReviewer(ctx context.Context, prID, reviewerID string) error
+	SetStatus(ctx context.Context, prID string, status domain.PRStatus) error
+	CountRecentReviewsByAuthor(ctx context.Context, authorID string, since time.Time) (map[string]int, error)
+	ApplyReviewerChanges(ctx context.Context, changes []domain.ReviewerChange) error
 }
 
 type UserRepository interface {
 	GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	GetByIDs(ctx context.Context, userIDs []string) ([]domain.User, error)
+}
+
+// Outbox записывает доменные события в транзакции изменения
+type Outbox interface {
+	Add(ctx context.Context, events ...domain.OutboxEvent) error
+}
+
+// Writeback переносит назначения ревьюеров во внешнюю систему PR после коммита
+type Writeback interface {
+	// Schedule вызывается в транзакции изменения
+	Schedule(ctx context.Context, wb domain.VCSWriteback) error
+	Wake()
+}
+
+// CodeOwners определяет владельцев измененных файлов по CODEOWNERS команды
+type CodeOwners interface {
+	OwnedFiles(ctx context.Context, teamName string, files []string) (map[string]int, error)
 }
 
 type PullRequestService struct {
-	prRepo    PullRequestRepository
-	userRepo  UserRepository
-	txManager database.TransactionManagerInterface
-	lg        *slog.Logger
+	prRepo       PullRequestRepository
+	userRepo     UserRepository
+	outbox       Outbox
+	writeback    Writeback
+	codeOwners   CodeOwners
+	txManager    database.TransactionManagerInterface
+	maxReviewers int
+	strategy     domain.ReviewerStrategy
+	pairLookback time.Duration
+	lg           *slog.Logger
 }
 
 func NewPullRequestService(prRepo PullRequestRepository,
 	userRepo UserRepository,
+	outbox Outbox,
+	writeback Writeback,
+	codeOwners CodeOwners,
 	txManager database.TransactionManagerInterface,
+	maxReviewers int,
+	strategy domain.ReviewerStrategy,
+	pairLookback time.Duration,
 	lg *slog.Logger) *PullRequestService {
 	return &PullRequestService{
-		prRepo:    prRepo,
-		userRepo:  userRepo,
-		txManager: txManager,
-		lg:        lg,
+		prRepo:       prRepo,
+		userRepo:     userRepo,
+		outbox:       outbox,
+		writeback:    writeback,
+		codeOwners:   codeOwners,
+		txManager:    txManager,
+		maxReviewers: maxReviewers,
+		strategy:     strategy,
+		pairLookback: pairLookback,
+		lg:           lg,
 	}
 }
 
@@ -55,6 +96,13 @@ func (s *PullRequestService) CreatePullRequest(ctx context.Context, prReqInfo do
 	)
 
 	var pr *domain.PullRequest
+	var selection *domain.ReviewerSelection
+
+	areas, err := domain.NormalizeTags(prReqInfo.RequiredAreas)
+	if err != nil {
+		return nil, err
+	}
+	prReqInfo.RequiredAreas = areas
 
 	// получим автора PR, проверим существует ли он, если да - определим название команды
 	author, err := s.getAuthor(ctx, prReqInfo.AuthorID)
@@ -81,59 +129,169 @@ func (s *PullRequestService) CreatePullRequest(ctx context.Context, prReqInfo do
 		}
 		log.Info("found reviewer candidates", slog.Int("count", len(candidates)))
 
-		// берем до двух случайных ревьюеров
-		reviewers := reviewers.ChooseRandomReviewers(candidates, 2)
-		reviewerIDs := make([]string, len(reviewers))
-		for i, r := range reviewers {
+		chosen, sel, err := s.chooseReviewers(txCtx, log, author.TeamName, prReqInfo, candidates)
+		if err != nil {
+			return err
+		}
+		selection = sel
+		reviewerIDs := make([]string, len(chosen))
+		for i, r := range chosen {
 			reviewerIDs[i] = r.UserID
 		}
 		log.Info("selected PR reviewers", slog.Any("reviewer_ids", reviewerIDs))
 
-		// создаем PR
-		_, err = s.prRepo.CreatePullRequest(txCtx, prReqInfo)
-		if err != nil {
-			return fmt.Errorf("failed to create PR: %w", err)
-		}
-
-		// назначем ревьюеров на PR
-		for _, reviewerID := range reviewerIDs {
-			if err := s.prRepo.AssignReviewer(txCtx, reviewerID, prReqInfo.ID); err != nil {
-				return fmt.Errorf("failed to assign PR reviewer %s: %w", reviewerID, err)
-			}
+		// создаем PR сразу с назначенными ревьюерами
+		createdPR, err := s.prRepo.CreatePullRequest(txCtx, prReqInfo, reviewerIDs)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			// PR с тем же id создали параллельно после проверки выше
+			return domain.ErrPRExists
 		}
-
-		// получаем созданный PR
-		createdPR, err := s.prRepo.GetPullRequestByID(txCtx, prReqInfo.ID)
 		if err != nil {
-			return fmt.Errorf("failed to get created PR: %w", err)
+			return fmt.Errorf("failed to create PR: %w", err)
 		}
 		pr = createdPR
 
-		return nil
+		if err := s.addEvent(txCtx, domain.EventPRCreated, pr.ID, domain.PREvent{PullRequest: pr}); err != nil {
+			return err
+		}
+		return s.writeback.Schedule(txCtx, domain.VCSWriteback{
+			PRID:             pr.ID,
+			AddedReviewerIDs: reviewerIDs,
+			Reason:           "CREATED",
+		})
 	})
 
 	if err != nil {
 		log.Error("failed to create PR", slog.Any("error", err))
 		return nil, err
 	}
+	s.writeback.Wake()
 	log.Info("PR created")
+	pr.Selection = selection
 	return pr, nil
 }
 
-func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+// chooseReviewers берет до maxReviewers (по умолчанию двух) ревьюеров: сначала покрывающих
+// области PR, затем владельцев измененных файлов (больше файлов - выше приоритет), затем
+// при стратегии avoid_repeats - реже ревьюивших автора за pairLookback, затем случайных участников.
+// selection - nil только при стратегии random без областей PR
+func (s *PullRequestService) chooseReviewers(ctx context.Context, log *slog.Logger, teamName string,
+	prReqInfo domain.CreatePRRequest, candidates []domain.User) ([]domain.User, *domain.ReviewerSelection, error) {
+	owned, err := s.codeOwners.OwnedFiles(ctx, teamName, prReqInfo.ChangedFiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve code owners: %w", err)
+	}
+	if len(owned) > 0 {
+		log.Info("found code owners", slog.Any("owned_files", owned))
+	}
+
+	var recent map[string]int
+	if s.strategy == domain.ReviewerStrategyAvoidRepeats {
+		recent, err = s.prRepo.CountRecentReviewsByAuthor(ctx, prReqInfo.AuthorID, time.Now().Add(-s.pairLookback))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to count recent reviews: %w", err)
+		}
+		log.Info("found recent author reviews", slog.Any("recent_reviews", recent))
+	}
+
+	areas := prReqInfo.RequiredAreas
+	uncovered := []string{}
+	var chosen []domain.User
+	switch {
+	case len(areas) > 0:
+		chosen, uncovered = reviewers.ChooseSkilledReviewers(candidates, areas, owned, recent, s.maxReviewers)
+		if len(uncovered) > 0 {
+			log.Warn("required areas are not covered by reviewers", slog.Any("uncovered_areas", uncovered))
+		}
+	case len(owned) > 0 || len(recent) > 0:
+		chosen = reviewers.ChoosePreferredReviewers(candidates, owned, recent, s.maxReviewers)
+	default:
+		chosen = reviewers.ChooseRandomReviewers(candidates, s.maxReviewers)
+	}
+
+	if s.strategy == domain.ReviewerStrategyRandom && len(areas) == 0 {
+		return chosen, nil, nil
+	}
+
+	selection := &domain.ReviewerSelection{
+		Strategy:       s.strategy,
+		UncoveredAreas: uncovered,
+		Reviewers:      make([]domain.ReviewerRationale, len(chosen)),
+	}
+	if recent != nil {
+		selection.PairLookback = s.pairLookback.String()
+	}
+	for i, reviewer := range chosen {
+		selection.Reviewers[i] = explainReviewer(reviewer, areas, owned, recent)
+	}
+	return chosen, selection, nil
+}
+
+// explainReviewer описывает, почему выбран ревьюер; recent == nil - история пар не учитывалась
+func explainReviewer(reviewer domain.User, areas []string, owned, recent map[string]int) domain.ReviewerRationale {
+	rationale := domain.ReviewerRationale{
+		UserID:     reviewer.UserID,
+		OwnedFiles: owned[reviewer.UserID],
+	}
+	var reasons []string
+
+	for _, area := range areas {
+		if slices.Contains(reviewer.Skills, area) {
+			rationale.CoveredAreas = append(rationale.CoveredAreas, area)
+		}
+	}
+	if len(rationale.CoveredAreas) > 0 {
+		reasons = append(reasons, "covers "+strings.Join(rationale.CoveredAreas, ", "))
+	}
+	if rationale.OwnedFiles > 0 {
+		reasons = append(reasons, fmt.Sprintf("owns %d changed file(s)", rationale.OwnedFiles))
+	}
+
+	if recent != nil {
+		count := recent[reviewer.UserID]
+		rationale.RecentReviews = &count
+		if count == 0 {
+			reasons = append(reasons, "has not reviewed the author recently")
+		} else {
+			reasons = append(reasons, fmt.Sprintf("reviewed %d of the author's recent PRs", count))
+		}
+	}
+
+	if len(reasons) == 0 {
+		reasons = append(reasons, "random active team member")
+	}
+	rationale.Reason = strings.Join(reasons, "; ")
+	return rationale
+}
+
+// MergePullRequest помечает PR как MERGED. expectedVersion - версии из If-Match, nil - без проверки.
+// Повторный merge не меняет PR и его версию
+func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string, expectedVersion []int64) (*domain.PullRequest, error) {
 	log := s.lg.With(
 		slog.String("merge PR, pr_id", prID),
 	)
 
 	var pr *domain.PullRequest
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
-		// проверяем, что PR существует
-		exists, err := s.prRepo.Exists(txCtx, prID)
+		// проверяем, что PR существует, и блокируем его до конца транзакции
+		current, err := s.prRepo.GetPullRequestByIDForUpdate(txCtx, prID)
 		if err != nil {
-			return fmt.Errorf("failed to check PR existence: %w", err)
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to lock PR: %w", err)
 		}
-		if !exists {
-			return domain.ErrPRNotFound
+
+		if err := domain.CheckVersion(expectedVersion, current.Version); err != nil {
+			return err
+		}
+
+		if current.IsPRMerged() {
+			pr = current
+			return nil
+		}
+		if current.IsPRClosed() {
+			return domain.ErrPRClosed
 		}
 
 		// выполняем merge
@@ -148,7 +306,7 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string)
 		}
 		pr = mergedPR
 
-		return nil
+		return s.addEvent(txCtx, domain.EventPRMerged, pr.ID, domain.PREvent{PullRequest: pr})
 	})
 
 	if err != nil {
@@ -158,7 +316,9 @@ func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string)
 	return pr, nil
 }
 
-func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevReviewerID string) (*domain.PullRequest, string, error) {
+// ReassignReviewer заменяет ревьюера на случайного активного участника его команды.
+// expectedVersion - версии PR из If-Match, nil - без проверки
+func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevReviewerID string, expectedVersion []int64) (*domain.PullRequest, string, error) {
 	log := s.lg.With(
 		slog.String("reassign PR, pr_id", prID),
 		slog.String("old_user_id", prevReviewerID),
@@ -168,8 +328,9 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevRev
 	var newReviewerID string
 
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
-		// проверяем, что PR существует
-		pr, err := s.prRepo.GetPullRequestByID(txCtx, prID)
+		// проверяем, что PR существует, и блокируем его: статус и список ревьюеров
+		// не изменятся параллельным переназначением, merge или деактивацией до конца транзакции
+		pr, err := s.prRepo.GetPullRequestByIDForUpdate(txCtx, prID)
 		if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
 				return domain.ErrPRNotFound
@@ -177,11 +338,19 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevRev
 			return fmt.Errorf("failed to get PR: %w", err)
 		}
 
+		// PR изменился с момента, когда клиент его прочитал
+		if err := domain.CheckVersion(expectedVersion, pr.Version); err != nil {
+			return err
+		}
+
 		// проверяем, что статус PR - НЕ MERGED
 		if pr.IsPRMerged() {
 			log.Error("cannot reassign on merged PR")
 			return domain.ErrPRMerged
 		}
+		if pr.IsPRClosed() {
+			return domain.ErrPRClosed
+		}
 
 		// проверяем, что старый ревьюер назначен на PR
 		isAssigned, err := s.prRepo.IsReviewerAssigned(txCtx, prID, prevReviewerID)
@@ -194,7 +363,7 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevRev
 		}
 
 		// находим команду старого ревьюера
-		prevReviewer, err := s.userRepo.GetByID(ctx, prevReviewerID)
+		prevReviewer, err := s.userRepo.GetByID(txCtx, prevReviewerID)
 		if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
 				return domain.ErrUserNotFound
@@ -227,12 +396,15 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevRev
 		reviewerID := reviewer.UserID
 		log.Info("selected PR reviewer", slog.String("reviewer_id", reviewerID))
 
-		if err := s.prRepo.RemoveReviewer(txCtx, prevReviewerID, prID); err != nil {
-			return fmt.Errorf("failed to remove old reviewer: %w", err)
-		}
-
-		if err := s.prRepo.AssignReviewer(txCtx, reviewerID, prID); err != nil {
-			return fmt.Errorf("failed to assign new reviewer: %w", err)
+		// заменяем ревьюера на его же месте в списке и записываем переназначение в историю
+		err = s.prRepo.ApplyReviewerChanges(txCtx, []domain.ReviewerChange{{
+			PRID:          prID,
+			OldReviewerID: prevReviewerID,
+			NewReviewerID: reviewerID,
+			Reason:        domain.ReassignReasonManual,
+		}})
+		if err != nil {
+			return fmt.Errorf("failed to replace reviewer: %w", err)
 		}
 
 		// получаем PR с обновленным ревьюером
@@ -243,16 +415,211 @@ func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, prevRev
 		updPR = pr
 		newReviewerID = reviewerID
 
-		return nil
+		err = s.addEvent(txCtx, domain.EventReviewerReassigned, prID, domain.ReviewerReassignedEvent{
+			PRID:          prID,
+			OldReviewerID: prevReviewerID,
+			NewReviewerID: reviewerID,
+			Reason:        domain.ReassignReasonManual,
+		})
+		if err != nil {
+			return err
+		}
+		return s.writeback.Schedule(txCtx, domain.VCSWriteback{
+			PRID:              prID,
+			AddedReviewerIDs:  []string{reviewerID},
+			RemovedReviewerID: prevReviewerID,
+			Reason:            string(domain.ReassignReasonManual),
+		})
 	})
 
 	if err != nil {
 		return nil, "", err
 	}
+	s.writeback.Wake()
 	log.Info("PR reassigned")
 	return updPR, newReviewerID, nil
 }
 
+// ClosePullRequest закрывает открытый PR без merge. Повторное закрытие не меняет PR
+func (s *PullRequestService) ClosePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.setStatus(ctx, prID, domain.PRStatusOpen, domain.PRStatusClosed, domain.EventPRClosed)
+}
+
+// ReopenPullRequest снова открывает закрытый PR с прежними ревьюерами. Открытый PR не меняется
+func (s *PullRequestService) ReopenPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error) {
+	return s.setStatus(ctx, prID, domain.PRStatusClosed, domain.PRStatusOpen, domain.EventPRReopened)
+}
+
+// setStatus переводит PR из from в to. PR уже в статусе to возвращается без изменений, MERGED PR не меняется
+func (s *PullRequestService) setStatus(ctx context.Context, prID string, from, to domain.PRStatus, eventType domain.EventType) (*domain.PullRequest, error) {
+	var pr *domain.PullRequest
+	changed := false
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		current, err := s.prRepo.GetPullRequestByIDForUpdate(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to lock PR: %w", err)
+		}
+
+		switch current.Status {
+		case to:
+			pr = current
+			return nil
+		case domain.PRStatusMerged:
+			return domain.ErrPRMerged
+		case from:
+		default:
+			return fmt.Errorf("unexpected PR status %s", current.Status)
+		}
+
+		if err := s.prRepo.SetStatus(txCtx, prID, to); err != nil {
+			return err
+		}
+
+		pr, err = s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+		changed = true
+
+		return s.addEvent(txCtx, eventType, prID, domain.PREvent{PullRequest: pr})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		s.lg.Info("PR status changed", slog.String("pr_id", prID), slog.String("status", string(to)))
+	}
+	return pr, nil
+}
+
+// AddReviewer назначает ревьюера, запрошенного во внешней системе, в дополнение к уже назначенным.
+// Ограничение maxReviewers действует только на автоматический выбор. Для уже назначенного ревьюера
+// возвращает PR без изменений и added == false
+func (s *PullRequestService) AddReviewer(ctx context.Context, prID, reviewerID string) (pr *domain.PullRequest, added bool, err error) {
+	log := s.lg.With(
+		slog.String("add reviewer, pr_id", prID),
+		slog.String("reviewer_id", reviewerID),
+	)
+
+	err = s.txManager.Do(ctx, func(txCtx context.Context) error {
+		current, err := s.prRepo.GetPullRequestByIDForUpdate(txCtx, prID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrPRNotFound
+			}
+			return fmt.Errorf("failed to lock PR: %w", err)
+		}
+
+		if current.IsPRMerged() {
+			return domain.ErrPRMerged
+		}
+		if current.IsPRClosed() {
+			return domain.ErrPRClosed
+		}
+		if current.AuthorID == reviewerID {
+			return domain.ErrReviewerIsAuthor
+		}
+		if slices.Contains(current.AssignedReviewers, reviewerID) {
+			pr = current
+			return nil
+		}
+
+		reviewer, err := s.userRepo.GetByID(txCtx, reviewerID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get reviewer: %w", err)
+		}
+		if !reviewer.IsActive {
+			return domain.ErrUserInactive
+		}
+
+		if err := s.prRepo.AddReviewer(txCtx, prID, reviewerID); err != nil {
+			return err
+		}
+
+		pr, err = s.prRepo.GetPullRequestByID(txCtx, prID)
+		if err != nil {
+			return fmt.Errorf("failed to get updated PR: %w", err)
+		}
+		added = true
+
+		return s.addEvent(txCtx, domain.EventReviewerAssigned, prID, domain.ReviewerAssignedEvent{
+			PRID:       prID,
+			ReviewerID: reviewerID,
+			Reason:     domain.ReassignReasonRequested,
+		})
+	})
+
+	if err != nil {
+		return nil, false, err
+	}
+	if added {
+		log.Info("reviewer added")
+	}
+	return pr, added, nil
+}
+
+func (s *PullRequestService) GetPullRequest(ctx context.Context, prID string) (*domain.PullRequestDetails, error) {
+	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrPRNotFound
+		}
+		return nil, fmt.Errorf("failed to get PR: %w", err)
+	}
+
+	users, err := s.userRepo.GetByIDs(ctx, pr.AssignedReviewers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR reviewers: %w", err)
+	}
+
+	usersByID := make(map[string]domain.User, len(users))
+	for _, u := range users {
+		usersByID[u.UserID] = u
+	}
+
+	// сохраняем порядок ревьюеров из assigned_reviewers
+	reviewersInfo := make([]domain.ReviewerInfo, 0, len(pr.AssignedReviewers))
+	for _, reviewerID := range pr.AssignedReviewers {
+		info := domain.ReviewerInfo{UserID: reviewerID}
+		if u, ok := usersByID[reviewerID]; ok {
+			info.Username = u.Username
+			info.TeamName = u.TeamName
+			info.IsActive = u.IsActive
+		}
+		reviewersInfo = append(reviewersInfo, info)
+	}
+
+	return &domain.PullRequestDetails{
+		ID:                pr.ID,
+		Name:              pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: reviewersInfo,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		Version:           pr.Version,
+	}, nil
+}
+
+// addEvent записывает событие в outbox; вызывается внутри транзакции изменения
+func (s *PullRequestService) addEvent(ctx context.Context, eventType domain.EventType, aggregateID string, payload interface{}) error {
+	event, err := domain.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	if err := s.outbox.Add(ctx, event); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", eventType, err)
+	}
+	return nil
+}
+
 func (s *PullRequestService) getAuthor(ctx context.Context, authorID string) (*domain.User, error) {
 	author, err := s.userRepo.GetByID(ctx, authorID)
 	if err != nil {
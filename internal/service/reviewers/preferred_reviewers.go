@@ -0,0 +1,33 @@
+package reviewers
+
+import (
+	"math/rand/v2"
+	"sort"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// ChoosePreferredReviewers выбирает до maxCount ревьюеров: сначала кандидатов с положительным
+// весом по убыванию веса, при равном весе - с меньшим штрафом penalties, затем случайно
+func ChoosePreferredReviewers(candidates []domain.User, weights, penalties map[string]int, maxCount int) []domain.User {
+	shuffled := make([]domain.User, len(candidates))
+	for i, j := range rand.Perm(len(candidates)) {
+		shuffled[i] = candidates[j]
+	}
+
+	// после перемешивания стабильная сортировка оставляет равных кандидатов в случайном порядке,
+	// а кандидаты без веса и штрафа оказываются за кандидатами с весом и перед оштрафованными
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return preferred(shuffled[i], shuffled[j], weights, penalties)
+	})
+
+	return shuffled[:min(len(shuffled), maxCount)]
+}
+
+// preferred сообщает, что a предпочтительнее b: больше вес, при равном весе - меньше штраф
+func preferred(a, b domain.User, weights, penalties map[string]int) bool {
+	if wa, wb := weights[a.UserID], weights[b.UserID]; wa != wb {
+		return wa > wb
+	}
+	return penalties[a.UserID] < penalties[b.UserID]
+}
@@ -0,0 +1,88 @@
+package reviewers
+
+import (
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func TestChoosePreferredReviewers(t *testing.T) {
+	candidates := []domain.User{user("u1"), user("u2"), user("u3")}
+
+	tests := []struct {
+		name      string
+		weights   map[string]int
+		penalties map[string]int
+		maxCount  int
+		wantIDs   []string
+	}{
+		{
+			// u2 недавно ревьюил автора дважды, u1 - один раз
+			name:      "recent pairs rank lower",
+			penalties: map[string]int{"u1": 1, "u2": 2},
+			maxCount:  3,
+			wantIDs:   []string{"u3", "u1", "u2"},
+		},
+		{
+			name:      "penalised candidates fill seats last",
+			penalties: map[string]int{"u1": 1, "u3": 1},
+			maxCount:  1,
+			wantIDs:   []string{"u2"},
+		},
+		{
+			name:     "higher weight first",
+			weights:  map[string]int{"u1": 1, "u3": 4},
+			maxCount: 3,
+			wantIDs:  []string{"u3", "u1", "u2"},
+		},
+		{
+			// вес владельца кода важнее истории пар
+			name:      "weight outranks penalty",
+			weights:   map[string]int{"u1": 1},
+			penalties: map[string]int{"u1": 5},
+			maxCount:  1,
+			wantIDs:   []string{"u1"},
+		},
+		{
+			name:      "penalty breaks a weight tie",
+			weights:   map[string]int{"u1": 2, "u2": 2},
+			penalties: map[string]int{"u2": 1},
+			maxCount:  2,
+			wantIDs:   []string{"u1", "u2"},
+		},
+		{
+			name:      "more seats than candidates",
+			penalties: map[string]int{"u1": 1, "u2": 2, "u3": 3},
+			maxCount:  5,
+			wantIDs:   []string{"u1", "u2", "u3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range selectionRuns {
+				got := userIDs(ChoosePreferredReviewers(candidates, tt.weights, tt.penalties, tt.maxCount))
+				if !slices.Equal(got, tt.wantIDs) {
+					t.Fatalf("reviewers = %q, want %q", got, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestChoosePreferredReviewersShufflesTies(t *testing.T) {
+	candidates := []domain.User{user("u1"), user("u2"), user("u3")}
+	penalties := map[string]int{"u3": 1}
+
+	firsts := map[string]bool{}
+	for range selectionRuns {
+		chosen := ChoosePreferredReviewers(candidates, nil, penalties, 1)
+		firsts[chosen[0].UserID] = true
+	}
+
+	// равные u1 и u2 выбираются случайно, оштрафованный u3 - никогда
+	if firsts["u3"] || !firsts["u1"] || !firsts["u2"] {
+		t.Errorf("first reviewers over %d runs = %v, want both u1 and u2 and never u3", selectionRuns, firsts)
+	}
+}
@@ -0,0 +1,63 @@
+package reviewers
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// ChooseSkilledReviewers выбирает до maxCount ревьюеров так, чтобы у каждой области из areas
+// был ревьюер с таким навыком. Кандидаты берутся жадно: следующим становится тот, кто покрывает
+// больше еще не покрытых областей, при равенстве - с большим весом weights, затем с меньшим
+// штрафом penalties, затем случайный.
+// Оставшиеся места заполняются как в ChoosePreferredReviewers.
+// Возвращает выбранных ревьюеров и области, которые покрыть не удалось
+func ChooseSkilledReviewers(candidates []domain.User, areas []string, weights, penalties map[string]int, maxCount int) ([]domain.User, []string) {
+	uncovered := slices.Clone(areas)
+	rest := make([]domain.User, len(candidates))
+	for i, j := range rand.Perm(len(candidates)) {
+		rest[i] = candidates[j]
+	}
+
+	var chosen []domain.User
+	for len(chosen) < maxCount && len(uncovered) > 0 {
+		best, bestCovered := -1, 0
+		for i, c := range rest {
+			covered := countCovered(c, uncovered)
+			if covered == 0 {
+				continue
+			}
+			if covered > bestCovered || (covered == bestCovered && preferred(c, rest[best], weights, penalties)) {
+				best, bestCovered = i, covered
+			}
+		}
+		// ни у кого из оставшихся нет навыков непокрытых областей
+		if best < 0 {
+			break
+		}
+
+		reviewer := rest[best]
+		chosen = append(chosen, reviewer)
+		rest = slices.Delete(rest, best, best+1)
+		uncovered = slices.DeleteFunc(uncovered, func(area string) bool {
+			return slices.Contains(reviewer.Skills, area)
+		})
+	}
+
+	chosen = append(chosen, ChoosePreferredReviewers(rest, weights, penalties, maxCount-len(chosen))...)
+	if uncovered == nil {
+		uncovered = []string{}
+	}
+	return chosen, uncovered
+}
+
+func countCovered(user domain.User, areas []string) int {
+	count := 0
+	for _, area := range areas {
+		if slices.Contains(user.Skills, area) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,130 @@
+package reviewers
+
+import (
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// выбор случаен при равенстве, поэтому каждый случай прогоняется несколько раз
+const selectionRuns = 50
+
+func user(id string, skills ...string) domain.User {
+	return domain.User{UserID: id, Skills: skills, IsActive: true}
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.UserID
+	}
+	return ids
+}
+
+func TestChooseSkilledReviewers(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []domain.User
+		areas      []string
+		weights    map[string]int
+		penalties  map[string]int
+		maxCount   int
+
+		wantIDs       []string
+		wantUncovered []string
+	}{
+		{
+			name:          "one reviewer covers all areas",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "go", "postgres"), user("u3", "frontend")},
+			areas:         []string{"go", "postgres"},
+			maxCount:      1,
+			wantIDs:       []string{"u2"},
+			wantUncovered: []string{},
+		},
+		{
+			name:          "each area gets its own reviewer",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "postgres"), user("u3")},
+			areas:         []string{"go", "postgres"},
+			weights:       map[string]int{"u2": 1, "u3": 5},
+			maxCount:      2,
+			wantIDs:       []string{"u2", "u1"},
+			wantUncovered: []string{},
+		},
+		{
+			name:          "partial coverage",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "frontend")},
+			areas:         []string{"go", "rust"},
+			maxCount:      1,
+			wantIDs:       []string{"u1"},
+			wantUncovered: []string{"rust"},
+		},
+		{
+			// жадный выбор: первым берется тот, кто покрывает больше областей
+			name:          "fewer seats than areas",
+			candidates:    []domain.User{user("u1", "frontend"), user("u2", "go", "postgres"), user("u3", "go")},
+			areas:         []string{"go", "postgres", "frontend"},
+			maxCount:      1,
+			wantIDs:       []string{"u2"},
+			wantUncovered: []string{"frontend"},
+		},
+		{
+			// места заполняются как в ChoosePreferredReviewers
+			name:          "no candidate has the skill",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "go")},
+			areas:         []string{"rust"},
+			weights:       map[string]int{"u2": 1},
+			maxCount:      1,
+			wantIDs:       []string{"u2"},
+			wantUncovered: []string{"rust"},
+		},
+		{
+			name:          "weight breaks a coverage tie",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "go"), user("u3", "go")},
+			areas:         []string{"go"},
+			weights:       map[string]int{"u3": 2},
+			maxCount:      1,
+			wantIDs:       []string{"u3"},
+			wantUncovered: []string{},
+		},
+		{
+			name:          "penalty breaks a coverage and weight tie",
+			candidates:    []domain.User{user("u1", "go"), user("u2", "go")},
+			areas:         []string{"go"},
+			penalties:     map[string]int{"u1": 3},
+			maxCount:      1,
+			wantIDs:       []string{"u2"},
+			wantUncovered: []string{},
+		},
+		{
+			name:          "no candidates",
+			areas:         []string{"go"},
+			maxCount:      2,
+			wantIDs:       []string{},
+			wantUncovered: []string{"go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range selectionRuns {
+				chosen, uncovered := ChooseSkilledReviewers(tt.candidates, tt.areas, tt.weights, tt.penalties, tt.maxCount)
+				if got := userIDs(chosen); !slices.Equal(got, tt.wantIDs) {
+					t.Fatalf("reviewers = %q, want %q", got, tt.wantIDs)
+				}
+				if !slices.Equal(uncovered, tt.wantUncovered) {
+					t.Fatalf("uncovered = %q, want %q", uncovered, tt.wantUncovered)
+				}
+			}
+		})
+	}
+}
+
+func TestChooseSkilledReviewersKeepsAreas(t *testing.T) {
+	areas := []string{"go", "postgres"}
+	ChooseSkilledReviewers([]domain.User{user("u1", "go")}, areas, nil, nil, 2)
+
+	if want := []string{"go", "postgres"}; !slices.Equal(areas, want) {
+		t.Errorf("areas = %q after selection, want %q", areas, want)
+	}
+}
@@ -1,14 +1,30 @@
 package service
 
 import (
+	"ynastt/avito_test_task_backend_2025/internal/service/codeowners"
+	"ynastt/avito_test_task_backend_2025/internal/service/jobs"
+	"ynastt/avito_test_task_backend_2025/internal/service/outbox"
 	pr "ynastt/avito_test_task_backend_2025/internal/service/pullrequest"
+	"ynastt/avito_test_task_backend_2025/internal/service/snapshot"
 	"ynastt/avito_test_task_backend_2025/internal/service/team"
 	"ynastt/avito_test_task_backend_2025/internal/service/user"
+	"ynastt/avito_test_task_backend_2025/internal/service/vcs"
+	"ynastt/avito_test_task_backend_2025/internal/service/webhook"
 )
 
 type Services struct {
 	TeamService        *team.TeamService
+	CodeOwnersService  *codeowners.CodeOwnersService
 	UserService        *user.UserService
+	DeactivationJobs   *user.DeactivationJobService
+	JobService         *jobs.JobService
+	OutboxRelay        *outbox.Relay
+	WebhookService     *webhook.WebhookService
 	PullRequestService *pr.PullRequestService
+	IdentityService    *vcs.IdentityService
+	GitHubService      *vcs.GitHubService
+	GitLabService      *vcs.GitLabService
 	StatsService       *StatsService
+	SnapshotService    *snapshot.SnapshotService
+	HealthService      *HealthService
 }
@@ -0,0 +1,175 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/service/codeowners"
+)
+
+type SnapshotRepository interface {
+	IsEmpty(ctx context.Context) (bool, error)
+	ListTeams(ctx context.Context) ([]domain.SnapshotTeam, error)
+	ListUsers(ctx context.Context) ([]domain.SnapshotUser, error)
+	ListPullRequests(ctx context.Context) ([]domain.SnapshotPR, error)
+	ListReassignments(ctx context.Context) ([]domain.SnapshotReassignment, error)
+	ListCodeOwners(ctx context.Context) ([]domain.SnapshotCodeOwners, error)
+	InsertTeam(ctx context.Context, t domain.SnapshotTeam) error
+	InsertUser(ctx context.Context, u domain.SnapshotUser) error
+	InsertPullRequest(ctx context.Context, pr domain.SnapshotPR) error
+	InsertReassignment(ctx context.Context, ra domain.SnapshotReassignment) error
+	InsertCodeOwners(ctx context.Context, co domain.SnapshotCodeOwners) error
+}
+
+type TransactionManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type SnapshotService struct {
+	repo      SnapshotRepository
+	txManager TransactionManager
+	lg        *slog.Logger
+}
+
+func NewSnapshotService(repo SnapshotRepository,
+	txManager TransactionManager,
+	lg *slog.Logger) *SnapshotService {
+	return &SnapshotService{
+		repo:      repo,
+		txManager: txManager,
+		lg:        lg,
+	}
+}
+
+func (s *SnapshotService) Export(ctx context.Context) (*domain.Snapshot, error) {
+	snapshot := &domain.Snapshot{
+		Version:    domain.SnapshotVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	// все таблицы читаем в одной транзакции, чтобы снапшот был согласованным
+	err := s.txManager.DoReadOnly(ctx, func(txCtx context.Context) error {
+		var err error
+		if snapshot.Teams, err = s.repo.ListTeams(txCtx); err != nil {
+			return err
+		}
+		if snapshot.Users, err = s.repo.ListUsers(txCtx); err != nil {
+			return err
+		}
+		if snapshot.PullRequests, err = s.repo.ListPullRequests(txCtx); err != nil {
+			return err
+		}
+		if snapshot.Reassignments, err = s.repo.ListReassignments(txCtx); err != nil {
+			return err
+		}
+		if snapshot.CodeOwners, err = s.repo.ListCodeOwners(txCtx); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export snapshot: %w", err)
+	}
+
+	if snapshot.Teams == nil {
+		snapshot.Teams = []domain.SnapshotTeam{}
+	}
+	if snapshot.Users == nil {
+		snapshot.Users = []domain.SnapshotUser{}
+	}
+	if snapshot.PullRequests == nil {
+		snapshot.PullRequests = []domain.SnapshotPR{}
+	}
+	if snapshot.Reassignments == nil {
+		snapshot.Reassignments = []domain.SnapshotReassignment{}
+	}
+	if snapshot.CodeOwners == nil {
+		snapshot.CodeOwners = []domain.SnapshotCodeOwners{}
+	}
+
+	s.lg.Info("snapshot exported",
+		slog.Int("teams", len(snapshot.Teams)),
+		slog.Int("users", len(snapshot.Users)),
+		slog.Int("pull_requests", len(snapshot.PullRequests)),
+		slog.Int("reassignments", len(snapshot.Reassignments)),
+		slog.Int("codeowners", len(snapshot.CodeOwners)))
+
+	return snapshot, nil
+}
+
+// Import восстанавливает снапшот в пустую БД в одной транзакции
+func (s *SnapshotService) Import(ctx context.Context, snapshot *domain.Snapshot) (*domain.SnapshotImportResult, error) {
+	if snapshot.Version < domain.MinSnapshotVersion || snapshot.Version > domain.SnapshotVersion {
+		return nil, domain.ErrSnapshotVersion
+	}
+
+	// CODEOWNERS проверяются так же, как при загрузке через API, до записи в БД
+	for _, co := range snapshot.CodeOwners {
+		if _, err := codeowners.Parse(co.Content); err != nil {
+			return nil, fmt.Errorf("team %s: %w", co.TeamName, err)
+		}
+	}
+
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		empty, err := s.repo.IsEmpty(txCtx)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return domain.ErrDatabaseNotEmpty
+		}
+
+		// порядок вставки определяется внешними ключами
+		for _, t := range snapshot.Teams {
+			if err := s.repo.InsertTeam(txCtx, t); err != nil {
+				return err
+			}
+		}
+		for _, u := range snapshot.Users {
+			if err := s.repo.InsertUser(txCtx, u); err != nil {
+				return err
+			}
+		}
+		for _, pr := range snapshot.PullRequests {
+			if err := s.repo.InsertPullRequest(txCtx, pr); err != nil {
+				return err
+			}
+		}
+		for _, ra := range snapshot.Reassignments {
+			if err := s.repo.InsertReassignment(txCtx, ra); err != nil {
+				return err
+			}
+		}
+		for _, co := range snapshot.CodeOwners {
+			if err := s.repo.InsertCodeOwners(txCtx, co); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.SnapshotImportResult{
+		Teams:         len(snapshot.Teams),
+		Users:         len(snapshot.Users),
+		PullRequests:  len(snapshot.PullRequests),
+		Reassignments: len(snapshot.Reassignments),
+		CodeOwners:    len(snapshot.CodeOwners),
+	}
+
+	s.lg.Info("snapshot imported",
+		slog.Int("teams", result.Teams),
+		slog.Int("users", result.Users),
+		slog.Int("pull_requests", result.PullRequests),
+		slog.Int("reassignments", result.Reassignments),
+		slog.Int("codeowners", result.CodeOwners))
+
+	return result, nil
+}
@@ -20,23 +20,36 @@ func NewStatsService(statsRepo repository.StatsRepository, logger *slog.Logger)
 	}
 }
 
-func (s *StatsService) GetStats(ctx context.Context, includeDetails bool) (*domain.StatsResponse, error) {
+func (s *StatsService) GetStats(ctx context.Context, filter domain.StatsFilter, includeDetails bool) (*domain.StatsResponse, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Получаем основную статистику
 	stats, err := s.statsRepo.GetTotalStats(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	// Статистика за окно и/или по команде
+	if !filter.IsEmpty() {
+		window, err := s.statsRepo.GetWindowStats(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		stats.Window = window
+	}
+
 	// Детализированная статистика при необходимости
 	if includeDetails {
-		userStats, err := s.statsRepo.GetUserAssignmentStats(ctx)
+		userStats, err := s.statsRepo.GetUserAssignmentStats(ctx, filter)
 		if err != nil {
 			s.logger.Warn("failed to get user assignment stats", "error", err)
 		} else {
 			stats.UserAssignments = userStats
 		}
 
-		prStats, err := s.statsRepo.GetPRAssignmentStats(ctx)
+		prStats, err := s.statsRepo.GetPRAssignmentStats(ctx, filter)
 		if err != nil {
 			s.logger.Warn("failed to get PR assignment stats", "error", err)
 		} else {
@@ -52,3 +65,53 @@ func (s *StatsService) GetStats(ctx context.Context, includeDetails bool) (*doma
 
 	return stats, nil
 }
+
+func (s *StatsService) GetCycleTime(ctx context.Context, filter domain.StatsFilter) (*domain.CycleTimeStats, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.statsRepo.GetTeamCycleTime(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers, err := s.statsRepo.GetReviewerCycleTime(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.CycleTimeStats{
+		From:      filter.From,
+		To:        filter.To,
+		TeamName:  filter.TeamName,
+		Teams:     teams,
+		Reviewers: reviewers,
+	}
+	if stats.Teams == nil {
+		stats.Teams = []domain.TeamCycleTime{}
+	}
+	if stats.Reviewers == nil {
+		stats.Reviewers = []domain.ReviewerCycleTime{}
+	}
+
+	s.logger.Info("cycle time stats retrieved",
+		"teams", len(stats.Teams),
+		"reviewers", len(stats.Reviewers))
+
+	return stats, nil
+}
+
+func (s *StatsService) StreamUserAssignments(ctx context.Context, filter domain.StatsFilter, fn func(domain.UserAssignmentStats) error) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return s.statsRepo.StreamUserAssignmentStats(ctx, filter, fn)
+}
+
+func (s *StatsService) StreamPRAssignments(ctx context.Context, filter domain.StatsFilter, fn func(domain.PRAssignmentStats) error) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	return s.statsRepo.StreamPRAssignmentStats(ctx, filter, fn)
+}
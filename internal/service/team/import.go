@@ -0,0 +1,260 @@
+package team
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+)
+
+// ParseImport читает записи импорта в формате csv (с заголовком team,user_id,username,is_active)
+// или yaml (список объектов с теми же ключами). Пустой is_active в csv означает true
+func ParseImport(r io.Reader, format string) ([]domain.ImportRecord, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return parseImportCSV(r)
+	case domain.ImportFormatYAML:
+		return parseImportYAML(r)
+	default:
+		return nil, domain.ErrImportFormat
+	}
+}
+
+func parseImportCSV(r io.Reader) ([]domain.ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"team", "user_id", "username"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header must contain %q column", required)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []domain.ImportRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv line %d: %w", line, err)
+		}
+
+		isActive := true
+		if value := field(row, "is_active"); value != "" {
+			isActive, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid is_active on line %d: %q", line, value)
+			}
+		}
+
+		records = append(records, domain.ImportRecord{
+			Line:     line,
+			TeamName: field(row, "team"),
+			UserID:   field(row, "user_id"),
+			Username: field(row, "username"),
+			IsActive: isActive,
+		})
+	}
+
+	return records, nil
+}
+
+func parseImportYAML(r io.Reader) ([]domain.ImportRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yaml: %w", err)
+	}
+
+	var raw []struct {
+		TeamName string `yaml:"team"`
+		UserID   string `yaml:"user_id"`
+		Username string `yaml:"username"`
+		IsActive *bool  `yaml:"is_active"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	records := make([]domain.ImportRecord, 0, len(raw))
+	for i, item := range raw {
+		isActive := true
+		if item.IsActive != nil {
+			isActive = *item.IsActive
+		}
+		records = append(records, domain.ImportRecord{
+			Line:     i + 1,
+			TeamName: strings.TrimSpace(item.TeamName),
+			UserID:   strings.TrimSpace(item.UserID),
+			Username: strings.TrimSpace(item.Username),
+			IsActive: isActive,
+		})
+	}
+
+	return records, nil
+}
+
+// ImportTeams строит план импорта (какие команды и пользователи будут созданы, обновлены или деактивированы)
+// и, если это не dry-run и конфликтов нет, применяет его в одной транзакции
+func (s *TeamService) ImportTeams(ctx context.Context, records []domain.ImportRecord, dryRun bool) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{
+		DryRun:           dryRun,
+		TeamsCreated:     []string{},
+		UsersCreated:     []string{},
+		UsersUpdated:     []string{},
+		UsersDeactivated: []string{},
+	}
+
+	// проверяем записи файла между собой
+	valid := make([]domain.ImportRecord, 0, len(records))
+	seen := make(map[string]domain.ImportRecord, len(records))
+	for _, rec := range records {
+		if rec.TeamName == "" || rec.UserID == "" || rec.Username == "" {
+			report.Conflicts = append(report.Conflicts, domain.ImportConflict{
+				Line:   rec.Line,
+				UserID: rec.UserID,
+				Reason: "team, user_id and username are required",
+			})
+			continue
+		}
+
+		if prev, ok := seen[rec.UserID]; ok {
+			if prev.TeamName != rec.TeamName || prev.Username != rec.Username || prev.IsActive != rec.IsActive {
+				report.Conflicts = append(report.Conflicts, domain.ImportConflict{
+					Line:   rec.Line,
+					UserID: rec.UserID,
+					Reason: fmt.Sprintf("user_id differs from line %d", prev.Line),
+				})
+			}
+			continue
+		}
+		seen[rec.UserID] = rec
+		valid = append(valid, rec)
+	}
+
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		// сравниваем с текущим состоянием БД
+		newTeams := make(map[string]bool)
+		// существующие команды, состав которых изменится, включая команды, из которых уходят пользователи
+		changedTeams := make(map[string]bool)
+		var toUpsert []domain.ImportRecord
+		for _, rec := range valid {
+			if _, checked := newTeams[rec.TeamName]; !checked {
+				exists, err := s.teamRepo.Exists(txCtx, rec.TeamName)
+				if err != nil {
+					return fmt.Errorf("failed to check team existence: %w", err)
+				}
+				newTeams[rec.TeamName] = !exists
+				if !exists {
+					report.TeamsCreated = append(report.TeamsCreated, rec.TeamName)
+				}
+			}
+
+			user, err := s.userRepo.GetByID(txCtx, rec.UserID)
+			switch {
+			case errors.Is(err, repository.ErrNotFound):
+				report.UsersCreated = append(report.UsersCreated, rec.UserID)
+				toUpsert = append(toUpsert, rec)
+				changedTeams[rec.TeamName] = !newTeams[rec.TeamName]
+			case err != nil:
+				return fmt.Errorf("failed to get user %s: %w", rec.UserID, err)
+			case user.TeamName != rec.TeamName || user.Username != rec.Username || user.IsActive != rec.IsActive:
+				report.UsersUpdated = append(report.UsersUpdated, rec.UserID)
+				toUpsert = append(toUpsert, rec)
+				changedTeams[rec.TeamName] = !newTeams[rec.TeamName]
+				if user.TeamName != "" && user.TeamName != rec.TeamName {
+					changedTeams[user.TeamName] = true
+				}
+				if user.IsActive && !rec.IsActive {
+					report.UsersDeactivated = append(report.UsersDeactivated, rec.UserID)
+				}
+			default:
+				report.Unchanged++
+			}
+		}
+
+		if dryRun || len(report.Conflicts) > 0 {
+			return nil
+		}
+
+		for _, teamName := range report.TeamsCreated {
+			if err := s.teamRepo.CreateTeam(txCtx, teamName); err != nil {
+				return fmt.Errorf("failed to create team %s: %w", teamName, err)
+			}
+		}
+
+		// версии существующих команд, состав которых меняется, увеличиваем до обновления пользователей:
+		// команды блокируются раньше пользователей, как и при деактивации
+		teamNames := make([]string, 0, len(changedTeams))
+		for teamName, changed := range changedTeams {
+			if changed {
+				teamNames = append(teamNames, teamName)
+			}
+		}
+		if err := s.teamRepo.BumpVersions(txCtx, teamNames); err != nil {
+			return err
+		}
+
+		// деактивация идет тем же путем, что и /users/setIsActive: открытые PR переназначаются,
+		// в outbox пишутся события. Пользователи, деактивируемые этим же импортом, заменой не станут
+		for _, userID := range report.UsersDeactivated {
+			if _, err := s.deactivator.Deactivate(txCtx, userID, report.UsersDeactivated); err != nil {
+				return fmt.Errorf("failed to deactivate user %s: %w", userID, err)
+			}
+		}
+
+		for _, rec := range toUpsert {
+			member := domain.TeamMember{UserID: rec.UserID, Username: rec.Username, IsActive: rec.IsActive}
+			if _, err := s.userRepo.Upsert(txCtx, member, rec.TeamName); err != nil {
+				return fmt.Errorf("failed to import user %s: %w", rec.UserID, err)
+			}
+		}
+		report.Applied = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun && len(report.Conflicts) > 0 {
+		return report, domain.ErrImportConflicts
+	}
+
+	s.lg.Info("teams import processed",
+		slog.Bool("dry_run", dryRun),
+		slog.Bool("applied", report.Applied),
+		slog.Int("teams_created", len(report.TeamsCreated)),
+		slog.Int("users_created", len(report.UsersCreated)),
+		slog.Int("users_updated", len(report.UsersUpdated)),
+		slog.Int("users_deactivated", len(report.UsersDeactivated)),
+		slog.Int("conflicts", len(report.Conflicts)))
+
+	return report, nil
+}
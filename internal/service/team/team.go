@@ -15,30 +15,40 @@ type TeamRepository interface {
 	CreateTeam(ctx context.Context, teamName string) error
 	Exists(ctx context.Context, teamName string) (bool, error)
 	GetTeam(ctx context.Context, teamName string) (*domain.Team, error)
+	GetVersion(ctx context.Context, teamName string) (int64, error)
+	BumpVersions(ctx context.Context, teamNames []string) error
 }
 
 type UserRepository interface {
-	Upsert(ctx context.Context, user domain.TeamMember, teamName string) error
+	Upsert(ctx context.Context, user domain.TeamMember, teamName string) (string, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
 }
 
+// UserDeactivator деактивирует пользователя с переназначением его открытых PR
+type UserDeactivator interface {
+	Deactivate(ctx context.Context, userID string, excludeUserIDs []string) ([]domain.PRsInfo, error)
+}
+
 type TeamService struct {
-	teamRepo  TeamRepository
-	userRepo  UserRepository
-	txManager database.TransactionManagerInterface
-	lg        *slog.Logger
+	teamRepo    TeamRepository
+	userRepo    UserRepository
+	deactivator UserDeactivator
+	txManager   database.TransactionManagerInterface
+	lg          *slog.Logger
 }
 
 func NewTeamService(teamRepo TeamRepository,
 	userRepo UserRepository,
+	deactivator UserDeactivator,
 	txManager database.TransactionManagerInterface,
 	lg *slog.Logger) *TeamService {
 	return &TeamService{
-		teamRepo:  teamRepo,
-		userRepo:  userRepo,
-		txManager: txManager,
-		lg:        lg,
+		teamRepo:    teamRepo,
+		userRepo:    userRepo,
+		deactivator: deactivator,
+		txManager:   txManager,
+		lg:          lg,
 	}
 }
 
@@ -56,11 +66,26 @@ func (s *TeamService) CreateTeam(ctx context.Context, team domain.Team) (*domain
 			return fmt.Errorf("failed to create team: %w", err)
 		}
 
+		// состав команд, из которых перешли пользователи, тоже меняется
+		var prevTeams []string
 		for _, member := range team.Members {
-			if err := s.userRepo.Upsert(txCtx, member, team.TeamName); err != nil {
+			prevTeam, err := s.userRepo.Upsert(txCtx, member, team.TeamName)
+			if err != nil {
 				return fmt.Errorf("failed to add team member %s: %w", member.UserID, err)
 			}
+			if prevTeam != "" && prevTeam != team.TeamName {
+				prevTeams = append(prevTeams, prevTeam)
+			}
+		}
+		if err := s.teamRepo.BumpVersions(txCtx, prevTeams); err != nil {
+			return err
+		}
+
+		version, err := s.teamRepo.GetVersion(txCtx, team.TeamName)
+		if err != nil {
+			return fmt.Errorf("failed to get team version: %w", err)
 		}
+		team.Version = version
 
 		return nil
 	})
@@ -0,0 +1,252 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// BulkDeactivationKind - вид задачи массовой деактивации в общей очереди
+const BulkDeactivationKind = "bulk_deactivation"
+
+type DeactivationJobRepository interface {
+	CreateJob(ctx context.Context, jobID string, userIDs []string) error
+	GetJob(ctx context.Context, jobID string) (*domain.DeactivationJob, error)
+	GetItems(ctx context.Context, jobID string) ([]domain.DeactivationJobItem, error)
+	CompleteItem(ctx context.Context, jobID string, item domain.DeactivationJobItem) error
+}
+
+type JobQueue interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) (*domain.Job, error)
+	Wake()
+	RunNow(ctx context.Context, kind string, payload interface{}, init func(ctx context.Context, job *domain.Job) error) (*domain.Job, error)
+}
+
+// DeactivationJobService выполняет массовую деактивацию задачей общей очереди с тем же id.
+// Результаты по каждому пользователю хранятся в БД, поэтому повторная попытка или
+// перезапуск продолжают задачу с первого необработанного пользователя
+type DeactivationJobService struct {
+	users     *UserService
+	jobRepo   DeactivationJobRepository
+	queue     JobQueue
+	txManager database.TransactionManagerInterface
+	workers   int
+	lg        *slog.Logger
+}
+
+func NewDeactivationJobService(users *UserService,
+	jobRepo DeactivationJobRepository,
+	queue JobQueue,
+	txManager database.TransactionManagerInterface,
+	workers int,
+	lg *slog.Logger) *DeactivationJobService {
+	return &DeactivationJobService{
+		users:     users,
+		jobRepo:   jobRepo,
+		queue:     queue,
+		txManager: txManager,
+		workers:   workers,
+		lg:        lg,
+	}
+}
+
+// BulkDeactivateUsers ставит задачу массовой деактивации в очередь и сразу возвращает ее
+func (s *DeactivationJobService) BulkDeactivateUsers(ctx context.Context, userIDs []string) (*domain.DeactivationJob, error) {
+	ids := normalizeUserIDs(userIDs)
+	if len(ids) == 0 {
+		return nil, domain.ErrEmptyUserIDs
+	}
+
+	var jobID string
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		job, err := s.queue.Enqueue(txCtx, BulkDeactivationKind, nil)
+		if err != nil {
+			return err
+		}
+		jobID = job.ID
+		return s.jobRepo.CreateJob(txCtx, jobID, ids)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deactivation job: %w", err)
+	}
+	s.lg.Info("deactivation job created", slog.String("job_id", jobID), slog.Int("users", len(ids)))
+
+	// задача видна исполнителям только после коммита
+	s.queue.Wake()
+
+	return s.GetJob(ctx, jobID)
+}
+
+// RunBulkDeactivation создает задачу и выполняет ее в текущем процессе, без исполнителей очереди.
+// Используется CLI
+func (s *DeactivationJobService) RunBulkDeactivation(ctx context.Context, userIDs []string) (*domain.DeactivationJob, error) {
+	ids := normalizeUserIDs(userIDs)
+	if len(ids) == 0 {
+		return nil, domain.ErrEmptyUserIDs
+	}
+
+	job, err := s.queue.RunNow(ctx, BulkDeactivationKind, nil, func(txCtx context.Context, job *domain.Job) error {
+		return s.jobRepo.CreateJob(txCtx, job.ID, ids)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run deactivation job: %w", err)
+	}
+
+	return s.GetJob(ctx, job.ID)
+}
+
+// GetJob возвращает задачу с прогрессом и результатами по уже обработанным пользователям
+func (s *DeactivationJobService) GetJob(ctx context.Context, jobID string) (*domain.DeactivationJob, error) {
+	job, err := s.jobRepo.GetJob(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	items, err := s.jobRepo.GetItems(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.BulkDeactivateResponse{
+		DeactivatedUserIDs: []string{},
+		PRsInfo:            []domain.PRsInfo{},
+	}
+	job.Total = len(items)
+	for _, item := range items {
+		switch item.Status {
+		case domain.JobItemDone:
+			job.Processed++
+			result.DeactivatedUserIDs = append(result.DeactivatedUserIDs, item.UserID)
+			result.PRsInfo = append(result.PRsInfo, item.PRsInfo...)
+		case domain.JobItemFailed:
+			job.Processed++
+			job.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("user %s: %s", item.UserID, item.Error))
+		}
+	}
+	job.Result = result
+
+	return job, nil
+}
+
+// Handle - обработчик задач BulkDeactivationKind общей очереди.
+// Статус задачи ведет очередь, здесь сохраняются только результаты по пользователям
+func (s *DeactivationJobService) Handle(ctx context.Context, job *domain.Job) error {
+	return s.process(ctx, job.ID)
+}
+
+// process обрабатывает необработанных пользователей задачи пулом из s.workers воркеров.
+// Пользователи раздаются в порядке position, и никто из задачи не назначается заменой другому
+func (s *DeactivationJobService) process(ctx context.Context, jobID string) error {
+	log := s.lg.With(slog.String("job_id", jobID))
+
+	items, err := s.jobRepo.GetItems(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	batchUserIDs := make([]string, len(items))
+	for i, item := range items {
+		batchUserIDs[i] = item.UserID
+	}
+
+	pending := make(chan domain.DeactivationJobItem)
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		persistErr error
+	)
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range pending {
+				// начатого пользователя доводим до конца даже при остановке сервера
+				if err := s.processItem(context.WithoutCancel(ctx), jobID, item, batchUserIDs); err != nil {
+					mu.Lock()
+					persistErr = errors.Join(persistErr, err)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, item := range items {
+		if item.Status != domain.JobItemPending {
+			continue
+		}
+		select {
+		case pending <- item:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	if persistErr != nil {
+		return persistErr
+	}
+	if err := ctx.Err(); err != nil {
+		log.Info("deactivation job interrupted", slog.Any("cause", context.Cause(ctx)))
+		return err
+	}
+
+	log.Info("deactivation job completed", slog.Int("users", len(items)))
+	return nil
+}
+
+// processItem деактивирует пользователя и в той же транзакции сохраняет результат,
+// поэтому после перезапуска пользователь не будет обработан повторно
+func (s *DeactivationJobService) processItem(ctx context.Context, jobID string, item domain.DeactivationJobItem, batchUserIDs []string) error {
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		_, prs, err := s.users.deactivateUser(txCtx, item.UserID, nil, batchUserIDs)
+		if err != nil {
+			return err
+		}
+
+		item.Status = domain.JobItemDone
+		item.PRsInfo = prs
+		return s.jobRepo.CompleteItem(txCtx, jobID, item)
+	})
+	if err == nil {
+		return nil
+	}
+
+	s.lg.Warn("failed to deactivate user in job",
+		slog.String("job_id", jobID),
+		slog.String("user_id", item.UserID),
+		slog.Any("error", err))
+
+	item.Status = domain.JobItemFailed
+	item.Error = err.Error()
+	item.PRsInfo = nil
+	return s.jobRepo.CompleteItem(ctx, jobID, item)
+}
+
+// normalizeUserIDs убирает пустые и повторяющиеся id и сортирует их,
+// чтобы порядок обработки не зависел от порядка в запросе
+func normalizeUserIDs(userIDs []string) []string {
+	seen := make(map[string]struct{}, len(userIDs))
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
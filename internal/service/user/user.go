@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"sync"
 
 	"ynastt/avito_test_task_backend_2025/internal/domain"
 	"ynastt/avito_test_task_backend_2025/internal/repository"
@@ -16,140 +15,160 @@ import (
 type UserRepository interface {
 	GetActiveUsersByTeam(ctx context.Context, teamName string, excludeUserIDs []string) ([]domain.User, error)
 	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	GetByIDForUpdate(ctx context.Context, userID string) (*domain.User, error)
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error)
+	SetSkills(ctx context.Context, userID string, skills []string) (*domain.User, error)
 }
 
 type PullRequestRepository interface {
 	GetPullRequestByID(ctx context.Context, prID string) (*domain.PullRequest, error)
-	GetPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
-	GetOpenPullRequestsByReviewer(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
-	RemoveReviewer(ctx context.Context, reviewerID, prID string) error
-	AssignReviewer(ctx context.Context, reviewerID, prID string) error
+	GetPullRequestsByReviewer(ctx context.Context, userID string, filter domain.ReviewerPRsFilter) ([]domain.PullRequestShort, error)
+	GetOpenPullRequestsByReviewerForUpdate(ctx context.Context, userID string) ([]domain.PullRequestShort, error)
+	ApplyReviewerChanges(ctx context.Context, changes []domain.ReviewerChange) error
+}
+
+type TeamRepository interface {
+	GetVersionForUpdate(ctx context.Context, teamName string) (int64, error)
+	BumpVersions(ctx context.Context, teamNames []string) error
+}
+
+// Outbox записывает доменные события в транзакции изменения
+type Outbox interface {
+	Add(ctx context.Context, events ...domain.OutboxEvent) error
 }
 
 type UserService struct {
 	userRepo  UserRepository
 	prRepo    PullRequestRepository
+	teamRepo  TeamRepository
+	outbox    Outbox
 	txManager database.TransactionManagerInterface
 	lg        *slog.Logger
 }
 
 func NewUserService(userRepo UserRepository,
 	prRepo PullRequestRepository,
+	teamRepo TeamRepository,
+	outbox Outbox,
 	txManager database.TransactionManagerInterface,
 	lg *slog.Logger) *UserService {
 	return &UserService{
 		userRepo:  userRepo,
 		prRepo:    prRepo,
+		teamRepo:  teamRepo,
+		outbox:    outbox,
 		txManager: txManager,
 		lg:        lg,
 	}
 }
 
-func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*domain.User, error) {
+// SetIsActive меняет активность пользователя. expectedTeamVersion - версии команды пользователя
+// из If-Match, nil - без проверки
+func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool, expectedTeamVersion []int64) (*domain.User, error) {
 	if !isActive {
-		user, _, err := s.deactivateUser(ctx, userID)
+		user, _, err := s.deactivateUser(ctx, userID, expectedTeamVersion, nil)
 		return user, err
 	}
 
-	user, err := s.userRepo.SetIsActive(ctx, userID, isActive)
-	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, domain.ErrUserNotFound
+	var user *domain.User
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		current, err := s.lockUserTeam(txCtx, userID, expectedTeamVersion)
+		if err != nil {
+			return err
+		}
+
+		user, err = s.userRepo.SetIsActive(txCtx, userID, isActive)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to set user active status: %w", err)
 		}
-		return nil, fmt.Errorf("failed to set user active status: %w", err)
+
+		if current.IsActive == isActive {
+			return nil
+		}
+		return s.teamRepo.BumpVersions(txCtx, []string{current.TeamName})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.lg.Info("user active status updated", slog.String("user_id", userID), slog.Bool("is_active", isActive))
 	return user, nil
 }
 
-// метод массовой деактивации
-func (s *UserService) BulkDeactivateUsers(ctx context.Context, userIDs []string) (*domain.BulkDeactivateResponse, error) {
-	if len(userIDs) == 0 {
-		return &domain.BulkDeactivateResponse{
-			DeactivatedUserIDs: []string{},
-			PRsInfo:            []domain.PRsInfo{},
-		}, domain.ErrEmptyUserIDs
+// SetSkills заменяет навыки пользователя. Навыки не меняют состав команды, поэтому версия команды не меняется
+func (s *UserService) SetSkills(ctx context.Context, userID string, skills []string) (*domain.User, error) {
+	skills, err := domain.NormalizeTags(skills)
+	if err != nil {
+		return nil, err
 	}
 
-	var (
-		deactivatedUserIDs []string
-		reassignedPRs      []domain.PRsInfo
-		errors             []string
-	)
-
-	type userResult struct {
-		userID string
-		user   *domain.User
-		prs    []domain.PRsInfo
-		err    error
+	user, err := s.userRepo.SetSkills(ctx, userID, skills)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to set user skills: %w", err)
 	}
 
-	// обрабатываем пользователей в горутинах
-	results := make(chan userResult, len(userIDs))
-	var wg sync.WaitGroup
-
-	for _, userID := range userIDs {
-		wg.Add(1)
-		go func(uid string) {
-			defer wg.Done()
-
-			user, prs, err := s.deactivateUser(ctx, uid)
-			results <- userResult{
-				userID: uid,
-				user:   user,
-				prs:    prs,
-				err:    err,
-			}
-		}(userID)
-	}
+	s.lg.Info("user skills updated", slog.String("user_id", userID), slog.Any("skills", skills))
+	return user, nil
+}
 
-	// закрываем канал после завершения всех горутин
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Собираем результаты
-	for result := range results {
-		if result.err != nil {
-			errors = append(errors, fmt.Sprintf("user %s: %v", result.userID, result.err))
-			continue
-		}
+// Deactivate деактивирует пользователя и переназначает его открытые PR в транзакции ctx, если она уже открыта.
+// excludeUserIDs не рассматриваются как замена - например, остальные деактивируемые тем же импортом
+func (s *UserService) Deactivate(ctx context.Context, userID string, excludeUserIDs []string) ([]domain.PRsInfo, error) {
+	_, prs, err := s.deactivateUser(ctx, userID, nil, excludeUserIDs)
+	return prs, err
+}
 
-		if result.user != nil {
-			deactivatedUserIDs = append(deactivatedUserIDs, result.user.UserID)
+// lockUserTeam блокирует команду пользователя и проверяет ее версию.
+// Команда блокируется раньше пользователя и его PR: тот же порядок, что и при деактивации, исключает взаимоблокировки.
+// У пользователя без команды блокировать нечего, а версия из If-Match ни с чем не совпадет
+func (s *UserService) lockUserTeam(ctx context.Context, userID string, expectedTeamVersion []int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
 		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
-		if len(result.prs) > 0 {
-			reassignedPRs = append(reassignedPRs, result.prs...)
+	if user.TeamName == "" {
+		if expectedTeamVersion != nil {
+			return nil, domain.ErrVersionConflict
 		}
+		return user, nil
 	}
 
-	// формируем ответ
-	response := &domain.BulkDeactivateResponse{
-		DeactivatedUserIDs: deactivatedUserIDs,
-		PRsInfo:            reassignedPRs,
+	version, err := s.teamRepo.GetVersionForUpdate(ctx, user.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock team %s: %w", user.TeamName, err)
 	}
 
-	if len(errors) > 0 {
-		response.Errors = errors
+	if err := domain.CheckVersion(expectedTeamVersion, version); err != nil {
+		return nil, err
 	}
 
-	s.lg.Info("bulk deactivation completed",
-		slog.Int("count deactivated users", len(deactivatedUserIDs)),
-		slog.Int("errors", len(errors)))
-
-	return response, nil
+	return user, nil
 }
 
-func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domain.User, []domain.PRsInfo, error) {
+// deactivateUser деактивирует пользователя и переназначает его открытые PR.
+// excludeUserIDs не рассматриваются как замена - например, остальные пользователи той же массовой деактивации
+func (s *UserService) deactivateUser(ctx context.Context, userID string, expectedTeamVersion []int64, excludeUserIDs []string) (*domain.User, []domain.PRsInfo, error) {
 	var user *domain.User
 	var prs []domain.PRsInfo
 
 	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
-		oldUser, err := s.userRepo.GetByID(txCtx, userID)
+		if _, err := s.lockUserTeam(txCtx, userID, expectedTeamVersion); err != nil {
+			return err
+		}
+
+		// блокируем пользователя: повторная параллельная деактивация дождется этой
+		// и увидит, что пользователь уже неактивен
+		oldUser, err := s.userRepo.GetByIDForUpdate(txCtx, userID)
 		if err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
 				return domain.ErrUserNotFound
@@ -162,32 +181,49 @@ func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domai
 			return nil
 		}
 
-		openPRs, err := s.prRepo.GetOpenPullRequestsByReviewer(txCtx, userID)
+		// блокируем открытые PR пользователя, чтобы их не смержили и не переназначили,
+		// пока подбираются замены
+		openPRs, err := s.prRepo.GetOpenPullRequestsByReviewerForUpdate(txCtx, userID)
 		if err != nil {
 			return fmt.Errorf("failed to get open PRs for reviewer: %w", err)
 		}
 
+		// сначала подбираем замены для всех PR, затем применяем их одним батчем
+		changes := make([]domain.ReviewerChange, 0, len(openPRs))
 		for _, prShort := range openPRs {
-			newReviewerID, reassignStatus, err := s.replacePRReviewer(txCtx, prShort.ID, userID, oldUser.TeamName)
+			change, reassignStatus, err := s.planPRReviewerReplacement(txCtx, prShort.ID, userID, oldUser.TeamName, excludeUserIDs)
 			if err != nil {
 				return fmt.Errorf("failed to handle PR %s replacement: %w", prShort.ID, err)
 			}
+			changes = append(changes, change)
 
 			prInfo := domain.PRsInfo{
 				PRID:           prShort.ID,
 				OldReviewerID:  userID,
-				NewReviewerID:  newReviewerID,
+				NewReviewerID:  change.NewReviewerID,
 				ReassignStatus: reassignStatus,
 			}
 
 			prs = append(prs, prInfo)
 		}
 
+		if err := s.prRepo.ApplyReviewerChanges(txCtx, changes); err != nil {
+			return err
+		}
+
 		user, err = s.userRepo.SetIsActive(txCtx, userID, false)
 		if err != nil {
 			return fmt.Errorf("failed to deactivate user: %w", err)
 		}
 
+		if err := s.teamRepo.BumpVersions(txCtx, []string{oldUser.TeamName}); err != nil {
+			return err
+		}
+
+		if err := s.addDeactivationEvents(txCtx, oldUser, changes, prs); err != nil {
+			return err
+		}
+
 		s.lg.Info("user deactivated",
 			slog.String("user_id", userID),
 			slog.Int("prs_processed", len(openPRs)))
@@ -196,8 +232,8 @@ func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domai
 	})
 
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			return nil, prs, domain.ErrUserNotFound
+		if errors.Is(err, domain.ErrUserNotFound) || errors.Is(err, domain.ErrVersionConflict) {
+			return nil, prs, err
 		}
 		return nil, prs, fmt.Errorf("failed to deactivate user: %w", err)
 	}
@@ -205,89 +241,146 @@ func (s *UserService) deactivateUser(ctx context.Context, userID string) (*domai
 	return user, prs, nil
 }
 
-func (s *UserService) replacePRReviewer(
+// addDeactivationEvents записывает в outbox переназначения ревьюеров и саму деактивацию
+func (s *UserService) addDeactivationEvents(ctx context.Context, user *domain.User, changes []domain.ReviewerChange, prs []domain.PRsInfo) error {
+	events := make([]domain.OutboxEvent, 0, len(changes)+1)
+	for _, ch := range changes {
+		event, err := domain.NewEvent(domain.EventReviewerReassigned, ch.PRID, domain.ReviewerReassignedEvent{
+			PRID:          ch.PRID,
+			OldReviewerID: ch.OldReviewerID,
+			NewReviewerID: ch.NewReviewerID,
+			Reason:        ch.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+
+	if prs == nil {
+		prs = []domain.PRsInfo{}
+	}
+	event, err := domain.NewEvent(domain.EventUserDeactivated, user.UserID, domain.UserDeactivatedEvent{
+		UserID:   user.UserID,
+		TeamName: user.TeamName,
+		PRsInfo:  prs,
+	})
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+
+	if err := s.outbox.Add(ctx, events...); err != nil {
+		return fmt.Errorf("failed to write deactivation events: %w", err)
+	}
+	return nil
+}
+
+// planPRReviewerReplacement подбирает замену деактивируемому ревьюеру.
+// Если кандидатов нет, ревьюер будет удален без замены
+func (s *UserService) planPRReviewerReplacement(
 	ctx context.Context,
 	prID string,
-	OldReviewerID string,
+	oldReviewerID string,
 	teamName string,
-) (string, string, error) {
+	excludeUserIDs []string,
+) (domain.ReviewerChange, string, error) {
+	change := domain.ReviewerChange{
+		PRID:          prID,
+		OldReviewerID: oldReviewerID,
+		Reason:        domain.ReassignReasonDeactivation,
+	}
+
 	pr, err := s.prRepo.GetPullRequestByID(ctx, prID)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get PR %s: %w", prID, err)
+		return change, "", fmt.Errorf("failed to get PR %s: %w", prID, err)
 	}
 
 	excludeIDs := []string{pr.AuthorID}
 	excludeIDs = append(excludeIDs, pr.AssignedReviewers...)
+	excludeIDs = append(excludeIDs, excludeUserIDs...)
 
 	candidates, err := s.userRepo.GetActiveUsersByTeam(ctx, teamName, excludeIDs)
 	if err != nil {
 		s.lg.Warn("failed to get replacement candidates, removing reviewer",
 			slog.String("pr_id", prID),
-			slog.String("user_id", OldReviewerID),
+			slog.String("user_id", oldReviewerID),
 			slog.Any("error", err))
-		return "", string(domain.ReviewerRemoved), s.removeReviewer(ctx, OldReviewerID, prID)
+		return change, string(domain.ReviewerRemoved), nil
 	}
 
 	if len(candidates) == 0 {
 		s.lg.Info("no replacement candidates found, removing reviewer",
 			slog.String("pr_id", prID),
-			slog.String("user_id", OldReviewerID))
-		return "", string(domain.ReviewerRemoved), s.removeReviewer(ctx, OldReviewerID, prID)
+			slog.String("user_id", oldReviewerID))
+		return change, string(domain.ReviewerRemoved), nil
 	}
 
 	newReviewer, err := reviewers.ChooseRandomReviewer(candidates)
 	if err != nil {
 		s.lg.Warn("failed to select reviewer, removing",
 			slog.String("pr_id", prID),
-			slog.String("user_id", OldReviewerID))
-		return "", string(domain.ReviewerRemoved), s.removeReviewer(ctx, OldReviewerID, prID)
-	}
-
-	if err := s.removeReviewer(ctx, OldReviewerID, prID); err != nil {
-		return "", "", err
+			slog.String("user_id", oldReviewerID))
+		return change, string(domain.ReviewerRemoved), nil
 	}
 
-	if err := s.assignReviewer(ctx, newReviewer.UserID, prID); err != nil {
-		return "", "", err
-	}
+	change.NewReviewerID = newReviewer.UserID
 
 	s.lg.Info("reviewer reassigned during deactivation",
 		slog.String("pr_id", prID),
-		slog.String("old_user_id", OldReviewerID),
+		slog.String("old_user_id", oldReviewerID),
 		slog.String("new_user_id", newReviewer.UserID))
-	return newReviewer.UserID, string(domain.ReviewerReplaced), nil
+	return change, string(domain.ReviewerReplaced), nil
 }
 
-func (s *UserService) removeReviewer(ctx context.Context, userID, prID string) error {
-	if err := s.prRepo.RemoveReviewer(ctx, userID, prID); err != nil {
-		return fmt.Errorf("failed to remove reviewer: %w", err)
+func (s *UserService) GetUserReviewerPRs(ctx context.Context, userID string, filter domain.ReviewerPRsFilter) (*domain.UserReviewsResponse, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, domain.ErrInvalidInput
 	}
 
-	s.lg.Info("removed not active reviewer from PR",
-		slog.String("pr_id", prID),
-		slog.String("user_id", userID))
+	// без проверки неизвестный пользователь выглядел бы как пользователь без PR
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
-	return nil
-}
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = domain.DefaultPageLimit
+	case filter.Limit > domain.MaxPageLimit:
+		filter.Limit = domain.MaxPageLimit
+	}
+	pageSize := filter.Limit
 
-func (s *UserService) assignReviewer(ctx context.Context, userID, prID string) error {
-	if err := s.prRepo.AssignReviewer(ctx, userID, prID); err != nil {
-		return fmt.Errorf("failed to assign reviewer: %w", err)
+	// запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit = pageSize + 1
+	prs, err := s.prRepo.GetPullRequestsByReviewer(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review PRs: %w", err)
 	}
 
-	s.lg.Info("assigned reviewer for PR",
-		slog.String("pr_id", prID),
-		slog.String("user_id", userID))
+	response := &domain.UserReviewsResponse{
+		UserID:       userID,
+		PullRequests: prs,
+	}
 
-	return nil
-}
+	if len(prs) > pageSize {
+		response.PullRequests = prs[:pageSize]
+		last := response.PullRequests[pageSize-1]
+		// created_at NOT NULL (миграция 000002): без него курсор не построить,
+		// а пустой next_cursor клиент принял бы за конец списка
+		if last.CreatedAt == nil {
+			return nil, fmt.Errorf("PR %s has no created_at, cannot build cursor", last.ID)
+		}
+		response.NextCursor = domain.Cursor{CreatedAt: *last.CreatedAt, ID: last.ID}.Encode()
+	}
 
-func (s *UserService) GetUserReviewerPRs(ctx context.Context, userID string) ([]domain.PullRequestShort, error) {
-	prs, err := s.prRepo.GetPullRequestsByReviewer(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get review PRs: %w", err)
+	if response.PullRequests == nil {
+		response.PullRequests = []domain.PullRequestShort{}
 	}
 
-	s.lg.Info("retrieved review PRs", slog.String("user_id", userID), slog.Int("PR count", len(prs)))
-	return prs, nil
+	s.lg.Info("retrieved review PRs", slog.String("user_id", userID), slog.Int("pr_count", len(response.PullRequests)))
+	return response, nil
 }
@@ -0,0 +1,198 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+type PullRequestService interface {
+	CreatePullRequest(ctx context.Context, prReqInfo domain.CreatePRRequest) (*domain.PullRequest, error)
+	MergePullRequest(ctx context.Context, prID string, expectedVersion []int64) (*domain.PullRequest, error)
+	ClosePullRequest(ctx context.Context, prID string) (*domain.PullRequest, error)
+	ReopenPullRequest(ctx context.Context, prID string) (*domain.PullRequest, error)
+	AddReviewer(ctx context.Context, prID, reviewerID string) (*domain.PullRequest, bool, error)
+}
+
+type prAction int
+
+const (
+	actionOpened prAction = iota
+	actionReopened
+	actionMerged
+	actionClosed
+	actionReviewRequested
+)
+
+// prEvent - событие PR внешней системы, приведенное к общему виду.
+// Интеграции разбирают свой формат в prEvent, а остальное делает eventProcessor
+type prEvent struct {
+	Action prAction
+	PRID   string
+	Title  string
+	// логин автора, нужен для создания PR; пустой - автор неизвестен, и PR не создается
+	AuthorLogin string
+	// ревьюеры, запрошенные во внешней системе
+	ReviewerLogins []string
+}
+
+// eventProcessor применяет события к PR сервиса, сопоставляя логины пользователям.
+// Общий для всех интеграций
+type eventProcessor struct {
+	provider   domain.VCSProvider
+	prs        PullRequestService
+	identities *IdentityService
+	lg         *slog.Logger
+}
+
+// apply выполняет операцию события и заполняет Outcome и Reason в result.
+// Событие, которое нечего применить (логин не сопоставлен, PR не отслеживается, ревьюер не подходит),
+// дает IGNORED с причиной, а не ошибку: такие события безопасно доставлять повторно
+func (p *eventProcessor) apply(ctx context.Context, result *domain.VCSEventResult, event prEvent) error {
+	result.PullRequestID = event.PRID
+
+	var err error
+	switch event.Action {
+	case actionOpened:
+		err = p.create(ctx, result, event)
+	case actionReopened:
+		err = p.reopen(ctx, result, event)
+	case actionMerged:
+		_, err = p.prs.MergePullRequest(ctx, event.PRID, nil)
+		err = p.outcome(result, domain.VCSOutcomeMerged, err)
+	case actionClosed:
+		_, err = p.prs.ClosePullRequest(ctx, event.PRID)
+		err = p.outcome(result, domain.VCSOutcomeClosed, err)
+	case actionReviewRequested:
+		err = p.addReviewers(ctx, result, event.ReviewerLogins)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.lg.Info("vcs event handled",
+		slog.String("provider", string(p.provider)),
+		slog.String("action", result.Action),
+		slog.String("pr_id", result.PullRequestID),
+		slog.String("outcome", string(result.Outcome)),
+		slog.String("reason", result.Reason))
+	return nil
+}
+
+// create создает PR с автоматически выбранными ревьюерами и добавляет ревьюеров,
+// уже запрошенных во внешней системе
+func (p *eventProcessor) create(ctx context.Context, result *domain.VCSEventResult, event prEvent) error {
+	if event.AuthorLogin == "" {
+		result.Outcome = domain.VCSOutcomeIgnored
+		result.Reason = "pull request author is unknown"
+		return nil
+	}
+
+	authorID, ok, err := p.identities.Resolve(ctx, p.provider, event.AuthorLogin)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		result.Outcome = domain.VCSOutcomeIgnored
+		result.Reason = fmt.Sprintf("author login %s is not mapped to a user", event.AuthorLogin)
+		return nil
+	}
+
+	_, err = p.prs.CreatePullRequest(ctx, domain.CreatePRRequest{
+		ID:       event.PRID,
+		Name:     event.Title,
+		AuthorID: authorID,
+	})
+	if err := p.outcome(result, domain.VCSOutcomeCreated, err); err != nil || result.Outcome != domain.VCSOutcomeCreated {
+		return err
+	}
+
+	if len(event.ReviewerLogins) == 0 {
+		return nil
+	}
+	if err := p.addReviewers(ctx, result, event.ReviewerLogins); err != nil {
+		return err
+	}
+	// PR создан, даже если запрошенных ревьюеров добавить не удалось
+	result.Outcome = domain.VCSOutcomeCreated
+	return nil
+}
+
+// reopen снова открывает PR. PR, открытый до подключения вебхука, создается
+func (p *eventProcessor) reopen(ctx context.Context, result *domain.VCSEventResult, event prEvent) error {
+	_, err := p.prs.ReopenPullRequest(ctx, event.PRID)
+	if errors.Is(err, domain.ErrPRNotFound) {
+		return p.create(ctx, result, event)
+	}
+	return p.outcome(result, domain.VCSOutcomeReopened, err)
+}
+
+func (p *eventProcessor) addReviewers(ctx context.Context, result *domain.VCSEventResult, logins []string) error {
+	var skipped []string
+	for _, login := range logins {
+		reviewerID, ok, err := p.identities.Resolve(ctx, p.provider, login)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("reviewer login %s is not mapped to a user", login))
+			continue
+		}
+
+		_, added, err := p.prs.AddReviewer(ctx, result.PullRequestID, reviewerID)
+		if err != nil {
+			if !isIgnorable(err) {
+				return err
+			}
+			skipped = append(skipped, fmt.Sprintf("reviewer %s: %s", reviewerID, err))
+			continue
+		}
+		if !added {
+			skipped = append(skipped, fmt.Sprintf("reviewer %s is already assigned", reviewerID))
+			continue
+		}
+		result.AddedReviewers = append(result.AddedReviewers, reviewerID)
+	}
+
+	result.Outcome = domain.VCSOutcomeIgnored
+	if len(result.AddedReviewers) > 0 {
+		result.Outcome = domain.VCSOutcomeReviewerAssigned
+	}
+	if len(logins) == 0 {
+		skipped = append(skipped, "no user reviewers requested")
+	}
+	result.Reason = strings.Join(skipped, "; ")
+	return nil
+}
+
+// outcome записывает результат операции: успех - outcome, ожидаемая ошибка - IGNORED с причиной
+func (p *eventProcessor) outcome(result *domain.VCSEventResult, outcome domain.VCSEventOutcome, err error) error {
+	switch {
+	case err == nil:
+		result.Outcome = outcome
+		return nil
+	case isIgnorable(err):
+		result.Outcome = domain.VCSOutcomeIgnored
+		result.Reason = err.Error()
+		return nil
+	default:
+		return err
+	}
+}
+
+// isIgnorable - ошибка означает, что событие не применимо к текущему состоянию PR
+func isIgnorable(err error) bool {
+	for _, target := range []error{
+		domain.ErrPRExists, domain.ErrPRNotFound, domain.ErrPRMerged, domain.ErrPRClosed,
+		domain.ErrUserNotFound, domain.ErrReviewerIsAuthor, domain.ErrUserInactive,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
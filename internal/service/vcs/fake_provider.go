@@ -0,0 +1,129 @@
+package vcs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// FakeCall - вызов FakeProvider
+type FakeCall struct {
+	Method string
+	PR     PRRef
+	Logins []string
+	Key    string
+	Body   string
+	// ошибка, которую вернул вызов (см. FailNext)
+	Err error
+}
+
+// FakeProvider - VCSProvider в памяти: запоминает вызовы и пишет их в лог.
+// Нужен для проверки записи ревьюеров без доступа к внешней системе
+type FakeProvider struct {
+	name domain.VCSProvider
+	lg   *slog.Logger
+
+	mu       sync.Mutex
+	calls    []FakeCall
+	failures map[string]fakeFailure
+	// ключи оставленных комментариев: повтор PostComment с тем же ключом ничего не публикует
+	comments map[string]string
+}
+
+type fakeFailure struct {
+	err error
+	// вызов успел выполниться, потерялся только ответ
+	applied bool
+}
+
+func NewFakeProvider(name domain.VCSProvider, lg *slog.Logger) *FakeProvider {
+	return &FakeProvider{name: name, lg: lg}
+}
+
+func (p *FakeProvider) Name() domain.VCSProvider {
+	return p.name
+}
+
+func (p *FakeProvider) RequestReviewers(_ context.Context, pr PRRef, logins []string) error {
+	return p.record(FakeCall{Method: "RequestReviewers", PR: pr, Logins: logins}, nil)
+}
+
+func (p *FakeProvider) RemoveReviewer(_ context.Context, pr PRRef, login string) error {
+	return p.record(FakeCall{Method: "RemoveReviewer", PR: pr, Logins: []string{login}}, nil)
+}
+
+func (p *FakeProvider) PostComment(_ context.Context, pr PRRef, key, body string) error {
+	return p.record(FakeCall{Method: "PostComment", PR: pr, Key: key, Body: body}, func() {
+		if _, ok := p.comments[key]; ok {
+			return
+		}
+		if p.comments == nil {
+			p.comments = make(map[string]string)
+		}
+		p.comments[key] = body
+	})
+}
+
+// FailNext заставляет следующий вызов method (например, "PostComment") вернуть err,
+// как при недоступности внешней системы
+func (p *FakeProvider) FailNext(method string, err error) {
+	p.setFailure(method, fakeFailure{err: err})
+}
+
+// LoseNextResponse выполняет следующий вызов method, но возвращает err,
+// как при обрыве соединения после того, как внешняя система применила запрос
+func (p *FakeProvider) LoseNextResponse(method string, err error) {
+	p.setFailure(method, fakeFailure{err: err, applied: true})
+}
+
+func (p *FakeProvider) setFailure(method string, failure fakeFailure) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures == nil {
+		p.failures = make(map[string]fakeFailure)
+	}
+	p.failures[method] = failure
+}
+
+// Calls возвращает копию записанных вызовов
+func (p *FakeProvider) Calls() []FakeCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]FakeCall(nil), p.calls...)
+}
+
+// Comments возвращает оставленные комментарии по ключам
+func (p *FakeProvider) Comments() map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	comments := make(map[string]string, len(p.comments))
+	for key, body := range p.comments {
+		comments[key] = body
+	}
+	return comments
+}
+
+// record запоминает вызов и применяет его эффект apply, если вызов не упал до внешней системы
+func (p *FakeProvider) record(call FakeCall, apply func()) error {
+	p.mu.Lock()
+	failure := p.failures[call.Method]
+	delete(p.failures, call.Method)
+	call.Err = failure.err
+	if apply != nil && (failure.err == nil || failure.applied) {
+		apply()
+	}
+	p.calls = append(p.calls, call)
+	p.mu.Unlock()
+
+	p.lg.Info("fake vcs call",
+		slog.String("provider", string(p.name)),
+		slog.String("method", call.Method),
+		slog.String("repo", call.PR.Repo),
+		slog.Int("number", call.PR.Number),
+		slog.Any("logins", call.Logins),
+		slog.String("body", call.Body),
+		slog.Any("error", call.Err))
+	return call.Err
+}
@@ -0,0 +1,156 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return body
+}
+
+// fakeIdentityRepo хранит сопоставления логинов в памяти: provider -> login -> user_id
+type fakeIdentityRepo struct {
+	logins map[domain.VCSProvider]map[string]string
+}
+
+func newIdentities(provider domain.VCSProvider, loginToUserID map[string]string) *IdentityService {
+	repo := &fakeIdentityRepo{logins: map[domain.VCSProvider]map[string]string{provider: loginToUserID}}
+	return NewIdentityService(repo, nil, discardLogger())
+}
+
+func (r *fakeIdentityRepo) Upsert(_ context.Context, identity domain.VCSIdentity) (*domain.VCSIdentity, error) {
+	if r.logins[identity.Provider] == nil {
+		r.logins[identity.Provider] = map[string]string{}
+	}
+	r.logins[identity.Provider][identity.Login] = identity.UserID
+	return &identity, nil
+}
+
+func (r *fakeIdentityRepo) Resolve(_ context.Context, provider domain.VCSProvider, login string) (string, error) {
+	userID, ok := r.logins[provider][login]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return userID, nil
+}
+
+func (r *fakeIdentityRepo) List(_ context.Context, provider domain.VCSProvider) ([]domain.VCSIdentity, error) {
+	var identities []domain.VCSIdentity
+	for login, userID := range r.logins[provider] {
+		identities = append(identities, domain.VCSIdentity{Provider: provider, Login: login, UserID: userID})
+	}
+	return identities, nil
+}
+
+func (r *fakeIdentityRepo) Delete(_ context.Context, provider domain.VCSProvider, login string) (bool, error) {
+	_, ok := r.logins[provider][login]
+	delete(r.logins[provider], login)
+	return ok, nil
+}
+
+func (r *fakeIdentityRepo) LoginsByUserIDs(_ context.Context, provider domain.VCSProvider, userIDs []string) (map[string]string, error) {
+	logins := make(map[string]string)
+	for login, userID := range r.logins[provider] {
+		if slices.Contains(userIDs, userID) {
+			logins[userID] = login
+		}
+	}
+	return logins, nil
+}
+
+// fakePRService - PullRequestService в памяти с теми же ошибками, что и настоящий сервис.
+// Записывает вызовы в calls в виде "<метод> <pr_id> [<аргумент>]"
+type fakePRService struct {
+	prs   map[string]*domain.PullRequest
+	calls []string
+}
+
+func newFakePRService(prs ...domain.PullRequest) *fakePRService {
+	s := &fakePRService{prs: make(map[string]*domain.PullRequest, len(prs))}
+	for _, pr := range prs {
+		s.prs[pr.ID] = &pr
+	}
+	return s
+}
+
+func (s *fakePRService) CreatePullRequest(_ context.Context, req domain.CreatePRRequest) (*domain.PullRequest, error) {
+	s.calls = append(s.calls, fmt.Sprintf("create %s %s", req.ID, req.AuthorID))
+	if _, ok := s.prs[req.ID]; ok {
+		return nil, domain.ErrPRExists
+	}
+	pr := &domain.PullRequest{ID: req.ID, Name: req.Name, AuthorID: req.AuthorID, Status: domain.PRStatusOpen}
+	s.prs[req.ID] = pr
+	return pr, nil
+}
+
+func (s *fakePRService) MergePullRequest(_ context.Context, prID string, _ []int64) (*domain.PullRequest, error) {
+	s.calls = append(s.calls, "merge "+prID)
+	pr, ok := s.prs[prID]
+	switch {
+	case !ok:
+		return nil, domain.ErrPRNotFound
+	case pr.Status == domain.PRStatusClosed:
+		return nil, domain.ErrPRClosed
+	}
+	pr.Status = domain.PRStatusMerged
+	return pr, nil
+}
+
+func (s *fakePRService) ClosePullRequest(_ context.Context, prID string) (*domain.PullRequest, error) {
+	s.calls = append(s.calls, "close "+prID)
+	return s.setStatus(prID, domain.PRStatusClosed)
+}
+
+func (s *fakePRService) ReopenPullRequest(_ context.Context, prID string) (*domain.PullRequest, error) {
+	s.calls = append(s.calls, "reopen "+prID)
+	return s.setStatus(prID, domain.PRStatusOpen)
+}
+
+func (s *fakePRService) setStatus(prID string, status domain.PRStatus) (*domain.PullRequest, error) {
+	pr, ok := s.prs[prID]
+	switch {
+	case !ok:
+		return nil, domain.ErrPRNotFound
+	case pr.Status == domain.PRStatusMerged:
+		return nil, domain.ErrPRMerged
+	}
+	pr.Status = status
+	return pr, nil
+}
+
+func (s *fakePRService) AddReviewer(_ context.Context, prID, reviewerID string) (*domain.PullRequest, bool, error) {
+	s.calls = append(s.calls, fmt.Sprintf("add_reviewer %s %s", prID, reviewerID))
+	pr, ok := s.prs[prID]
+	switch {
+	case !ok:
+		return nil, false, domain.ErrPRNotFound
+	case pr.Status == domain.PRStatusMerged:
+		return nil, false, domain.ErrPRMerged
+	case pr.AuthorID == reviewerID:
+		return nil, false, domain.ErrReviewerIsAuthor
+	case slices.Contains(pr.AssignedReviewers, reviewerID):
+		return pr, false, nil
+	}
+	pr.AssignedReviewers = append(pr.AssignedReviewers, reviewerID)
+	return pr, true, nil
+}
@@ -0,0 +1,137 @@
+package vcs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const (
+	GitHubHeaderEvent     = "X-GitHub-Event"
+	GitHubHeaderDelivery  = "X-GitHub-Delivery"
+	GitHubHeaderSignature = "X-Hub-Signature-256"
+
+	githubSignaturePrefix = "sha256="
+)
+
+// GitHubService переводит события pull_request из GitHub в операции над PR сервиса
+type GitHubService struct {
+	secret    string
+	processor *eventProcessor
+}
+
+// secret - секрет вебхука из настроек репозитория GitHub; пустой секрет отключает интеграцию
+func NewGitHubService(secret string, prs PullRequestService, identities *IdentityService, lg *slog.Logger) *GitHubService {
+	return &GitHubService{
+		secret: secret,
+		processor: &eventProcessor{
+			provider:   domain.VCSGitHub,
+			prs:        prs,
+			identities: identities,
+			lg:         lg,
+		},
+	}
+}
+
+// VerifySignature проверяет заголовок X-Hub-Signature-256: sha256=hex(HMAC-SHA256(secret, body))
+func (s *GitHubService) VerifySignature(body []byte, signature string) error {
+	if s.secret == "" {
+		return domain.ErrVCSDisabled
+	}
+	if !strings.HasPrefix(signature, githubSignaturePrefix) {
+		return domain.ErrInvalidVCSSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write(body)
+	expected := githubSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return domain.ErrInvalidVCSSignature
+	}
+	return nil
+}
+
+// GitHubPRID - id PR сервиса для PR GitHub: <owner>/<repo>#<number>
+func GitHubPRID(repoFullName string, number int) string {
+	return fmt.Sprintf("%s#%d", repoFullName, number)
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// поля события pull_request, которые использует сервис
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number int        `json:"number"`
+		Title  string     `json:"title"`
+		Merged bool       `json:"merged"`
+		User   githubUser `json:"user"`
+	} `json:"pull_request"`
+	// у review_requested задан либо пользователь, либо команда
+	RequestedReviewer *githubUser `json:"requested_reviewer"`
+	Repository        struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// HandleEvent обрабатывает событие с проверенной подписью. event - заголовок X-GitHub-Event.
+// Обрабатываются действия opened, reopened, closed и review_requested события pull_request
+func (s *GitHubService) HandleEvent(ctx context.Context, event string, body []byte) (*domain.VCSEventResult, error) {
+	result := &domain.VCSEventResult{Provider: domain.VCSGitHub, Event: event, Outcome: domain.VCSOutcomeIgnored}
+
+	if event != "pull_request" {
+		result.Reason = "event is not handled"
+		return result, nil
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if payload.Repository.FullName == "" || payload.PullRequest.Number <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	result.Action = payload.Action
+	prEvent := prEvent{
+		PRID:        GitHubPRID(payload.Repository.FullName, payload.PullRequest.Number),
+		Title:       payload.PullRequest.Title,
+		AuthorLogin: payload.PullRequest.User.Login,
+	}
+
+	switch payload.Action {
+	case "opened":
+		prEvent.Action = actionOpened
+	case "reopened":
+		prEvent.Action = actionReopened
+	case "closed":
+		prEvent.Action = actionClosed
+		if payload.PullRequest.Merged {
+			prEvent.Action = actionMerged
+		}
+	case "review_requested":
+		// запросы ревью у команды GitHub не сопоставляются пользователям
+		prEvent.Action = actionReviewRequested
+		if payload.RequestedReviewer != nil {
+			prEvent.ReviewerLogins = []string{payload.RequestedReviewer.Login}
+		}
+	default:
+		result.PullRequestID = prEvent.PRID
+		result.Reason = "action is not handled"
+		return result, nil
+	}
+
+	if err := s.processor.apply(ctx, result, prEvent); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
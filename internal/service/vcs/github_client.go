@@ -0,0 +1,139 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// сколько байт ответа GitHub включать в ошибку
+const maxGitHubErrorBytes = 512
+
+// размер страницы при поиске уже оставленного комментария
+const gitHubCommentsPerPage = 100
+
+// GitHubClient - VCSProvider поверх GitHub REST API
+type GitHubClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// baseURL - https://api.github.com или адрес API GitHub Enterprise; token - токен с правом на pull requests
+func NewGitHubClient(baseURL, token string, timeout time.Duration) *GitHubClient {
+	return &GitHubClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *GitHubClient) Name() domain.VCSProvider {
+	return domain.VCSGitHub
+}
+
+// RequestReviewers запрашивает ревью; уже запрошенные ревьюеры не дублируются
+func (c *GitHubClient) RequestReviewers(ctx context.Context, pr PRRef, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+	path := fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", pr.Repo, pr.Number)
+	return c.do(ctx, http.MethodPost, path, map[string]interface{}{"reviewers": logins}, nil)
+}
+
+// RemoveReviewer отзывает запрос ревью; отзыв отсутствующего запроса не ошибка
+func (c *GitHubClient) RemoveReviewer(ctx context.Context, pr PRRef, login string) error {
+	path := fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", pr.Repo, pr.Number)
+	return c.do(ctx, http.MethodDelete, path, map[string]interface{}{"reviewers": []string{login}}, nil)
+}
+
+// PostComment добавляет комментарий в обсуждение PR. Комментарий несет скрытую метку key;
+// если комментарий с меткой уже есть (прошлая попытка дошла до GitHub), новый не создается
+func (c *GitHubClient) PostComment(ctx context.Context, pr PRRef, key, body string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", pr.Repo, pr.Number)
+	marker := commentMarker(key)
+
+	found, err := c.hasComment(ctx, path, marker)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+	return c.do(ctx, http.MethodPost, path, map[string]interface{}{"body": body + "\n\n" + marker}, nil)
+}
+
+// hasComment ищет комментарий с меткой marker среди комментариев PR
+func (c *GitHubClient) hasComment(ctx context.Context, path, marker string) (bool, error) {
+	for page := 1; ; page++ {
+		var comments []struct {
+			Body string `json:"body"`
+		}
+		pagePath := fmt.Sprintf("%s?per_page=%d&page=%d", path, gitHubCommentsPerPage, page)
+		if err := c.do(ctx, http.MethodGet, pagePath, nil, &comments); err != nil {
+			return false, err
+		}
+		for _, comment := range comments {
+			if strings.Contains(comment.Body, marker) {
+				return true, nil
+			}
+		}
+		if len(comments) < gitHubCommentsPerPage {
+			return false, nil
+		}
+	}
+}
+
+// commentMarker - HTML-комментарий, невидимый в обсуждении PR
+func commentMarker(key string) string {
+	return "<!-- reviewer-service:" + key + " -->"
+}
+
+// do выполняет запрос; payload == nil - запрос без тела, out != nil - куда декодировать ответ
+func (c *GitHubClient) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("github %s %s: failed to decode response: %w", method, path, err)
+			}
+			return nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxGitHubErrorBytes))
+	return fmt.Errorf("github %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+}
@@ -0,0 +1,78 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGitHubComments - комментарии одного PR в API GitHub
+type fakeGitHubComments struct {
+	mu     sync.Mutex
+	bodies []string
+	posts  int
+}
+
+func (f *fakeGitHubComments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/repos/octo-org/hello-world/issues/42/comments" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		comments := []map[string]string{}
+		if r.URL.Query().Get("page") == "1" {
+			for _, body := range f.bodies {
+				comments = append(comments, map[string]string{"body": body})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(comments)
+	case http.MethodPost:
+		var req struct {
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.bodies = append(f.bodies, req.Body)
+		f.posts++
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGitHubClientPostCommentOncePerKey(t *testing.T) {
+	comments := &fakeGitHubComments{bodies: []string{"LGTM"}}
+	srv := httptest.NewServer(comments)
+	defer srv.Close()
+
+	client := NewGitHubClient(srv.URL, "token", time.Second)
+	pr := PRRef{Repo: "octo-org/hello-world", Number: 42}
+
+	for range 2 {
+		if err := client.PostComment(context.Background(), pr, "writeback:job-1", "Reviewer service assigned @hubot."); err != nil {
+			t.Fatalf("PostComment() error = %v", err)
+		}
+	}
+	if err := client.PostComment(context.Background(), pr, "writeback:job-2", "Reviewer service assigned @monalisa."); err != nil {
+		t.Fatalf("PostComment() error = %v", err)
+	}
+
+	if comments.posts != 2 {
+		t.Fatalf("posted %d comments, want 2: %q", comments.posts, comments.bodies)
+	}
+	first := comments.bodies[1]
+	if !strings.HasPrefix(first, "Reviewer service assigned @hubot.") || !strings.Contains(first, commentMarker("writeback:job-1")) {
+		t.Errorf("comment body = %q, want text with the key marker", first)
+	}
+}
@@ -0,0 +1,294 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const githubTestPRID = "octo-org/hello-world#42"
+
+func TestGitHubVerifySignature(t *testing.T) {
+	opened := readFixture(t, "github/pull_request_opened.json")
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		wantErr   error
+	}{
+		{
+			// пример из документации GitHub по проверке доставок вебхуков
+			name:      "documented example",
+			secret:    "It's a Secret to Everybody",
+			body:      []byte("Hello, World!"),
+			signature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+		},
+		{
+			name:      "recorded delivery",
+			secret:    "webhook-secret",
+			body:      opened,
+			signature: "sha256=4a23b38e9e4829906207db9216a692332f0f5964513fa5325c299109a014cb81",
+		},
+		{
+			name:      "wrong secret",
+			secret:    "another-secret",
+			body:      opened,
+			signature: "sha256=4a23b38e9e4829906207db9216a692332f0f5964513fa5325c299109a014cb81",
+			wantErr:   domain.ErrInvalidVCSSignature,
+		},
+		{
+			name:      "tampered body",
+			secret:    "It's a Secret to Everybody",
+			body:      []byte("Hello, World?"),
+			signature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+			wantErr:   domain.ErrInvalidVCSSignature,
+		},
+		{
+			name:      "missing sha256 prefix",
+			secret:    "It's a Secret to Everybody",
+			body:      []byte("Hello, World!"),
+			signature: "757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+			wantErr:   domain.ErrInvalidVCSSignature,
+		},
+		{
+			// X-Hub-Signature со старым алгоритмом SHA-1 не принимается
+			name:      "sha1 signature",
+			secret:    "It's a Secret to Everybody",
+			body:      []byte("Hello, World!"),
+			signature: "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59",
+			wantErr:   domain.ErrInvalidVCSSignature,
+		},
+		{
+			name:    "missing signature",
+			secret:  "It's a Secret to Everybody",
+			body:    []byte("Hello, World!"),
+			wantErr: domain.ErrInvalidVCSSignature,
+		},
+		{
+			name:      "empty secret",
+			body:      []byte("Hello, World!"),
+			signature: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17",
+			wantErr:   domain.ErrVCSDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewGitHubService(tt.secret, newFakePRService(), newIdentities(domain.VCSGitHub, nil), discardLogger())
+
+			err := s.VerifySignature(tt.body, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifySignature() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubHandleEvent(t *testing.T) {
+	logins := map[string]string{
+		"octocat":  "u1",
+		"hubot":    "u2",
+		"monalisa": "u3",
+	}
+	openPR := domain.PullRequest{ID: githubTestPRID, AuthorID: "u1", Status: domain.PRStatusOpen}
+	closedPR := domain.PullRequest{ID: githubTestPRID, AuthorID: "u1", Status: domain.PRStatusClosed}
+	mergedPR := domain.PullRequest{ID: githubTestPRID, AuthorID: "u1", Status: domain.PRStatusMerged}
+
+	tests := []struct {
+		name        string
+		event       string
+		fixture     string
+		logins      map[string]string
+		existing    []domain.PullRequest
+		wantAction  string
+		wantOutcome domain.VCSEventOutcome
+		wantCalls   []string
+		wantAdded   []string
+		wantStatus  domain.PRStatus
+	}{
+		{
+			name:        "opened",
+			fixture:     "pull_request_opened.json",
+			logins:      logins,
+			wantAction:  "opened",
+			wantOutcome: domain.VCSOutcomeCreated,
+			wantCalls:   []string{"create " + githubTestPRID + " u1"},
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "opened by unmapped author",
+			fixture:     "pull_request_opened.json",
+			logins:      map[string]string{"hubot": "u2"},
+			wantAction:  "opened",
+			wantOutcome: domain.VCSOutcomeIgnored,
+		},
+		{
+			name:        "opened twice",
+			fixture:     "pull_request_opened.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "opened",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantCalls:   []string{"create " + githubTestPRID + " u1"},
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "closed with merged true",
+			fixture:     "pull_request_closed_merged.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "closed",
+			wantOutcome: domain.VCSOutcomeMerged,
+			wantCalls:   []string{"merge " + githubTestPRID},
+			wantStatus:  domain.PRStatusMerged,
+		},
+		{
+			name:        "closed with merged true for unknown PR",
+			fixture:     "pull_request_closed_merged.json",
+			logins:      logins,
+			wantAction:  "closed",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantCalls:   []string{"merge " + githubTestPRID},
+		},
+		{
+			name:        "closed with merged false",
+			fixture:     "pull_request_closed_unmerged.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "closed",
+			wantOutcome: domain.VCSOutcomeClosed,
+			wantCalls:   []string{"close " + githubTestPRID},
+			wantStatus:  domain.PRStatusClosed,
+		},
+		{
+			name:        "reopened",
+			fixture:     "pull_request_reopened.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{closedPR},
+			wantAction:  "reopened",
+			wantOutcome: domain.VCSOutcomeReopened,
+			wantCalls:   []string{"reopen " + githubTestPRID},
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			// PR открыли до подключения вебхука: автор берется из pull_request.user, а не из sender
+			name:        "reopened unknown PR",
+			fixture:     "pull_request_reopened.json",
+			logins:      logins,
+			wantAction:  "reopened",
+			wantOutcome: domain.VCSOutcomeCreated,
+			wantCalls:   []string{"reopen " + githubTestPRID, "create " + githubTestPRID + " u1"},
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "reopened merged PR",
+			fixture:     "pull_request_reopened.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{mergedPR},
+			wantAction:  "reopened",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantCalls:   []string{"reopen " + githubTestPRID},
+			wantStatus:  domain.PRStatusMerged,
+		},
+		{
+			name:        "review_requested for a user",
+			fixture:     "pull_request_review_requested_user.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "review_requested",
+			wantOutcome: domain.VCSOutcomeReviewerAssigned,
+			wantCalls:   []string{"add_reviewer " + githubTestPRID + " u2"},
+			wantAdded:   []string{"u2"},
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "review_requested for an unmapped user",
+			fixture:     "pull_request_review_requested_user.json",
+			logins:      map[string]string{"octocat": "u1"},
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "review_requested",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "review_requested for a team",
+			fixture:     "pull_request_review_requested_team.json",
+			logins:      logins,
+			existing:    []domain.PullRequest{openPR},
+			wantAction:  "review_requested",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantStatus:  domain.PRStatusOpen,
+		},
+		{
+			name:        "other event",
+			event:       "push",
+			fixture:     "pull_request_opened.json",
+			logins:      logins,
+			wantOutcome: domain.VCSOutcomeIgnored,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := newFakePRService(tt.existing...)
+			s := NewGitHubService("webhook-secret", prs, newIdentities(domain.VCSGitHub, tt.logins), discardLogger())
+
+			event := tt.event
+			if event == "" {
+				event = "pull_request"
+			}
+			result, err := s.HandleEvent(context.Background(), event, readFixture(t, "github/"+tt.fixture))
+			if err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+
+			if result.Provider != domain.VCSGitHub || result.Event != event || result.Action != tt.wantAction {
+				t.Errorf("result = %s/%s/%s, want github/%s/%s", result.Provider, result.Event, result.Action, event, tt.wantAction)
+			}
+			if result.Outcome != tt.wantOutcome {
+				t.Errorf("Outcome = %s, want %s (reason: %s)", result.Outcome, tt.wantOutcome, result.Reason)
+			}
+			if result.Outcome == domain.VCSOutcomeIgnored && result.Reason == "" {
+				t.Errorf("ignored event has no reason")
+			}
+			if event == "pull_request" && result.PullRequestID != githubTestPRID {
+				t.Errorf("PullRequestID = %q, want %q", result.PullRequestID, githubTestPRID)
+			}
+			if !slices.Equal(prs.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", prs.calls, tt.wantCalls)
+			}
+			if !slices.Equal(result.AddedReviewers, tt.wantAdded) {
+				t.Errorf("AddedReviewers = %v, want %v", result.AddedReviewers, tt.wantAdded)
+			}
+
+			var status domain.PRStatus
+			if pr, ok := prs.prs[githubTestPRID]; ok {
+				status = pr.Status
+			}
+			if status != tt.wantStatus {
+				t.Errorf("PR status = %q, want %q", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestGitHubHandleEventInvalidPayload(t *testing.T) {
+	s := NewGitHubService("webhook-secret", newFakePRService(), newIdentities(domain.VCSGitHub, nil), discardLogger())
+
+	for name, body := range map[string]string{
+		"not json":          "{",
+		"missing repo":      `{"action":"opened","pull_request":{"number":42}}`,
+		"missing PR number": `{"action":"opened","repository":{"full_name":"octo-org/hello-world"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.HandleEvent(context.Background(), "pull_request", []byte(body)); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("HandleEvent() error = %v, want %v", err, domain.ErrInvalidInput)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package vcs
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const (
+	GitLabHeaderEvent = "X-Gitlab-Event"
+	GitLabHeaderUUID  = "X-Gitlab-Event-UUID"
+	GitLabHeaderToken = "X-Gitlab-Token"
+
+	gitlabMergeRequestHook = "Merge Request Hook"
+)
+
+// GitLabService переводит события Merge Request Hook из GitLab в операции над PR сервиса
+type GitLabService struct {
+	token     string
+	processor *eventProcessor
+}
+
+// token - Secret token из настроек вебхука GitLab; пустой токен отключает интеграцию
+func NewGitLabService(token string, prs PullRequestService, identities *IdentityService, lg *slog.Logger) *GitLabService {
+	return &GitLabService{
+		token: token,
+		processor: &eventProcessor{
+			provider:   domain.VCSGitLab,
+			prs:        prs,
+			identities: identities,
+			lg:         lg,
+		},
+	}
+}
+
+// VerifyToken сравнивает заголовок X-Gitlab-Token с настроенным токеном.
+// GitLab не подписывает тело, а передает сам токен
+func (s *GitLabService) VerifyToken(token string) error {
+	if s.token == "" {
+		return domain.ErrVCSDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return domain.ErrInvalidVCSToken
+	}
+	return nil
+}
+
+// GitLabPRID - id PR сервиса для merge request GitLab: <group>/<project>!<iid>
+func GitLabPRID(projectPath string, iid int) string {
+	return fmt.Sprintf("%s!%d", projectPath, iid)
+}
+
+type gitlabUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// поля события Merge Request Hook, которые использует сервис
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	// пользователь, выполнивший действие
+	User    gitlabUser `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int    `json:"iid"`
+		Title    string `json:"title"`
+		Action   string `json:"action"`
+		AuthorID int64  `json:"author_id"`
+	} `json:"object_attributes"`
+	Reviewers []gitlabUser `json:"reviewers"`
+	Changes   struct {
+		Reviewers *struct {
+			Previous []gitlabUser `json:"previous"`
+			Current  []gitlabUser `json:"current"`
+		} `json:"reviewers"`
+	} `json:"changes"`
+}
+
+// HandleEvent обрабатывает событие с проверенным токеном. event - заголовок X-Gitlab-Event.
+// Обрабатываются действия open, reopen, merge, close и update события Merge Request Hook
+func (s *GitLabService) HandleEvent(ctx context.Context, event string, body []byte) (*domain.VCSEventResult, error) {
+	result := &domain.VCSEventResult{Provider: domain.VCSGitLab, Event: event, Outcome: domain.VCSOutcomeIgnored}
+
+	if event != gitlabMergeRequestHook {
+		result.Reason = "event is not handled"
+		return result, nil
+	}
+
+	var payload gitlabMergeRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if payload.ObjectKind != "merge_request" || payload.Project.PathWithNamespace == "" || payload.ObjectAttributes.IID <= 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	result.Action = payload.ObjectAttributes.Action
+	prEvent := prEvent{
+		PRID:  GitLabPRID(payload.Project.PathWithNamespace, payload.ObjectAttributes.IID),
+		Title: payload.ObjectAttributes.Title,
+	}
+	// в событии нет логина автора, только его числовой id. Логин известен, только если действие
+	// выполнил сам автор; иначе PR не создается, чтобы автором не стал, например, переоткрывший его
+	if payload.User.ID != 0 && payload.User.ID == payload.ObjectAttributes.AuthorID {
+		prEvent.AuthorLogin = payload.User.Username
+	}
+
+	switch payload.ObjectAttributes.Action {
+	case "open":
+		// в отличие от GitHub, ревьюеры, выбранные при создании, приходят в том же событии
+		prEvent.Action = actionOpened
+		prEvent.ReviewerLogins = usernames(payload.Reviewers)
+	case "reopen":
+		prEvent.Action = actionReopened
+	case "merge":
+		prEvent.Action = actionMerged
+	case "close":
+		prEvent.Action = actionClosed
+	case "update":
+		// update приходит на любое изменение; сервису важны только добавленные ревьюеры
+		if payload.Changes.Reviewers == nil {
+			result.PullRequestID = prEvent.PRID
+			result.Reason = "update does not change reviewers"
+			return result, nil
+		}
+		prEvent.Action = actionReviewRequested
+		prEvent.ReviewerLogins = addedUsernames(payload.Changes.Reviewers.Previous, payload.Changes.Reviewers.Current)
+	default:
+		result.PullRequestID = prEvent.PRID
+		result.Reason = "action is not handled"
+		return result, nil
+	}
+
+	if err := s.processor.apply(ctx, result, prEvent); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func usernames(users []gitlabUser) []string {
+	logins := make([]string, 0, len(users))
+	for _, u := range users {
+		logins = append(logins, u.Username)
+	}
+	return logins
+}
+
+// addedUsernames возвращает пользователей из current, которых не было в previous.
+// Удаление ревьюера в GitLab не снимает его в сервисе
+func addedUsernames(previous, current []gitlabUser) []string {
+	before := make(map[string]struct{}, len(previous))
+	for _, u := range previous {
+		before[normalizeLogin(u.Username)] = struct{}{}
+	}
+
+	var added []string
+	for _, u := range current {
+		if _, ok := before[normalizeLogin(u.Username)]; !ok {
+			added = append(added, u.Username)
+		}
+	}
+	return added
+}
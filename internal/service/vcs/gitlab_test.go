@@ -0,0 +1,81 @@
+package vcs
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const gitlabTestPRID = "platform/billing!17"
+
+func TestGitLabHandleEventAuthor(t *testing.T) {
+	logins := map[string]string{
+		"alice":   "u1",
+		"bob":     "u2",
+		"mallory": "u3",
+	}
+
+	tests := []struct {
+		name        string
+		fixture     string
+		existing    []domain.PullRequest
+		wantOutcome domain.VCSEventOutcome
+		wantCalls   []string
+		wantAdded   []string
+	}{
+		{
+			name:        "open",
+			fixture:     "merge_request_open.json",
+			wantOutcome: domain.VCSOutcomeCreated,
+			wantCalls:   []string{"create " + gitlabTestPRID + " u1", "add_reviewer " + gitlabTestPRID + " u2"},
+			wantAdded:   []string{"u2"},
+		},
+		{
+			name:        "reopen of unknown MR by its author",
+			fixture:     "merge_request_reopen_by_author.json",
+			wantOutcome: domain.VCSOutcomeCreated,
+			wantCalls:   []string{"reopen " + gitlabTestPRID, "create " + gitlabTestPRID + " u1"},
+		},
+		{
+			// автором стал бы mallory, а alice могла бы попасть в ревьюеры собственного MR
+			name:        "reopen of unknown MR by another user",
+			fixture:     "merge_request_reopen_by_maintainer.json",
+			wantOutcome: domain.VCSOutcomeIgnored,
+			wantCalls:   []string{"reopen " + gitlabTestPRID},
+		},
+		{
+			name:        "reopen of known MR by another user",
+			fixture:     "merge_request_reopen_by_maintainer.json",
+			existing:    []domain.PullRequest{{ID: gitlabTestPRID, AuthorID: "u1", Status: domain.PRStatusClosed}},
+			wantOutcome: domain.VCSOutcomeReopened,
+			wantCalls:   []string{"reopen " + gitlabTestPRID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := newFakePRService(tt.existing...)
+			s := NewGitLabService("webhook-token", prs, newIdentities(domain.VCSGitLab, logins), discardLogger())
+
+			result, err := s.HandleEvent(context.Background(), gitlabMergeRequestHook, readFixture(t, "gitlab/"+tt.fixture))
+			if err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+
+			if result.Outcome != tt.wantOutcome {
+				t.Errorf("Outcome = %s, want %s (reason: %s)", result.Outcome, tt.wantOutcome, result.Reason)
+			}
+			if result.PullRequestID != gitlabTestPRID {
+				t.Errorf("PullRequestID = %q, want %q", result.PullRequestID, gitlabTestPRID)
+			}
+			if !slices.Equal(prs.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", prs.calls, tt.wantCalls)
+			}
+			if !slices.Equal(result.AddedReviewers, tt.wantAdded) {
+				t.Errorf("AddedReviewers = %v, want %v", result.AddedReviewers, tt.wantAdded)
+			}
+		})
+	}
+}
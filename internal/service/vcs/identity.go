@@ -0,0 +1,130 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+)
+
+type IdentityRepository interface {
+	Upsert(ctx context.Context, identity domain.VCSIdentity) (*domain.VCSIdentity, error)
+	Resolve(ctx context.Context, provider domain.VCSProvider, login string) (string, error)
+	List(ctx context.Context, provider domain.VCSProvider) ([]domain.VCSIdentity, error)
+	Delete(ctx context.Context, provider domain.VCSProvider, login string) (bool, error)
+	LoginsByUserIDs(ctx context.Context, provider domain.VCSProvider, userIDs []string) (map[string]string, error)
+}
+
+type UserRepository interface {
+	GetByID(ctx context.Context, userID string) (*domain.User, error)
+}
+
+// IdentityService сопоставляет логины внешних систем пользователям сервиса.
+// Общий для всех интеграций: события GitHub и GitLab ссылаются на авторов и ревьюеров по логину,
+// логин GitLab - username
+type IdentityService struct {
+	repo     IdentityRepository
+	userRepo UserRepository
+	lg       *slog.Logger
+}
+
+func NewIdentityService(repo IdentityRepository, userRepo UserRepository, lg *slog.Logger) *IdentityService {
+	return &IdentityService{
+		repo:     repo,
+		userRepo: userRepo,
+		lg:       lg,
+	}
+}
+
+// Map сопоставляет логин пользователю; повторный вызов для того же логина заменяет пользователя
+func (s *IdentityService) Map(ctx context.Context, identity domain.VCSIdentity) (*domain.VCSIdentity, error) {
+	if !isKnownProvider(identity.Provider) {
+		return nil, domain.ErrUnknownVCSProvider
+	}
+	identity.Login = normalizeLogin(identity.Login)
+	if identity.Login == "" || identity.UserID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, identity.UserID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	saved, err := s.repo.Upsert(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	s.lg.Info("identity mapped",
+		slog.String("provider", string(saved.Provider)),
+		slog.String("login", saved.Login),
+		slog.String("user_id", saved.UserID))
+	return saved, nil
+}
+
+// List возвращает сопоставления; пустой provider - всех систем
+func (s *IdentityService) List(ctx context.Context, provider domain.VCSProvider) (*domain.IdentitiesResponse, error) {
+	if provider != "" && !isKnownProvider(provider) {
+		return nil, domain.ErrUnknownVCSProvider
+	}
+
+	identities, err := s.repo.List(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.IdentitiesResponse{Identities: identities}, nil
+}
+
+func (s *IdentityService) Delete(ctx context.Context, provider domain.VCSProvider, login string) error {
+	if !isKnownProvider(provider) {
+		return domain.ErrUnknownVCSProvider
+	}
+
+	deleted, err := s.repo.Delete(ctx, provider, normalizeLogin(login))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrIdentityNotFound
+	}
+	return nil
+}
+
+// Resolve возвращает user_id для логина. ok == false - логин не сопоставлен
+func (s *IdentityService) Resolve(ctx context.Context, provider domain.VCSProvider, login string) (userID string, ok bool, err error) {
+	userID, err = s.repo.Resolve(ctx, provider, normalizeLogin(login))
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to resolve %s login %s: %w", provider, login, err)
+	}
+	return userID, true, nil
+}
+
+// Logins возвращает логины пользователей в системе provider: user_id -> login
+func (s *IdentityService) Logins(ctx context.Context, provider domain.VCSProvider, userIDs []string) (map[string]string, error) {
+	if len(userIDs) == 0 {
+		return map[string]string{}, nil
+	}
+	logins, err := s.repo.LoginsByUserIDs(ctx, provider, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s logins: %w", provider, err)
+	}
+	return logins, nil
+}
+
+func isKnownProvider(provider domain.VCSProvider) bool {
+	return provider == domain.VCSGitHub || provider == domain.VCSGitLab
+}
+
+// логины сравниваются без учета регистра
+func normalizeLogin(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
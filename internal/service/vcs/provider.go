@@ -0,0 +1,50 @@
+package vcs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// PRRef - PR во внешней системе: репозиторий (проект) и номер
+type PRRef struct {
+	Repo   string
+	Number int
+}
+
+// VCSProvider - API внешней системы, в которой живут PR. Через него решения сервиса
+// о ревьюерах становятся видны разработчикам. Пользователи передаются логинами этой системы.
+// Методы должны быть идемпотентными: задача записи повторяется после ошибки
+type VCSProvider interface {
+	Name() domain.VCSProvider
+	RequestReviewers(ctx context.Context, pr PRRef, logins []string) error
+	RemoveReviewer(ctx context.Context, pr PRRef, login string) error
+	// PostComment оставляет комментарий не больше одного раза на key: повтор с тем же key,
+	// в том числе после потерянного ответа на успешный запрос, не создает второй комментарий
+	PostComment(ctx context.Context, pr PRRef, key, body string) error
+}
+
+// parsePRID определяет систему и PR по id PR сервиса (см. GitHubPRID и GitLabPRID).
+// ok == false - PR создан не из внешней системы
+func parsePRID(prID string) (provider domain.VCSProvider, ref PRRef, ok bool) {
+	for _, p := range []struct {
+		provider  domain.VCSProvider
+		separator string
+	}{
+		{domain.VCSGitHub, "#"},
+		{domain.VCSGitLab, "!"},
+	} {
+		i := strings.LastIndex(prID, p.separator)
+		if i <= 0 || !strings.Contains(prID[:i], "/") {
+			continue
+		}
+		number, err := strconv.Atoi(prID[i+1:])
+		if err != nil || number <= 0 {
+			continue
+		}
+		return p.provider, PRRef{Repo: prID[:i], Number: number}, true
+	}
+	return "", PRRef{}, false
+}
@@ -0,0 +1,160 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// WritebackKind - вид задачи записи ревьюеров во внешнюю систему в общей очереди
+const WritebackKind = "vcs_writeback"
+
+type JobQueue interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) (*domain.Job, error)
+	Wake()
+}
+
+// WritebackService переносит назначения ревьюеров во внешние системы: запрашивает ревью,
+// отзывает запрос у замененного ревьюера и оставляет комментарий с решением сервиса.
+// Запись выполняется задачей общей очереди после коммита изменения, с повторами при ошибках
+type WritebackService struct {
+	providers  map[domain.VCSProvider]VCSProvider
+	identities *IdentityService
+	queue      JobQueue
+	lg         *slog.Logger
+}
+
+// providers - включенные внешние системы; PR остальных систем и PR, созданные вручную, пропускаются
+func NewWritebackService(identities *IdentityService, queue JobQueue, lg *slog.Logger, providers ...VCSProvider) *WritebackService {
+	byName := make(map[domain.VCSProvider]VCSProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &WritebackService{
+		providers:  byName,
+		identities: identities,
+		queue:      queue,
+		lg:         lg,
+	}
+}
+
+// Schedule ставит запись в очередь; вызывается в транзакции изменения,
+// поэтому откатившееся назначение не попадет во внешнюю систему
+func (s *WritebackService) Schedule(ctx context.Context, wb domain.VCSWriteback) error {
+	provider, _, ok := parsePRID(wb.PRID)
+	if !ok {
+		return nil
+	}
+	if _, ok := s.providers[provider]; !ok {
+		return nil
+	}
+
+	if _, err := s.queue.Enqueue(ctx, WritebackKind, wb); err != nil {
+		return fmt.Errorf("failed to schedule %s writeback: %w", provider, err)
+	}
+	return nil
+}
+
+// Wake будит исполнителей очереди после коммита
+func (s *WritebackService) Wake() {
+	s.queue.Wake()
+}
+
+// Handle - обработчик задач WritebackKind общей очереди
+func (s *WritebackService) Handle(ctx context.Context, job *domain.Job) error {
+	var wb domain.VCSWriteback
+	if err := json.Unmarshal(job.Payload, &wb); err != nil {
+		return fmt.Errorf("invalid writeback job payload: %w", err)
+	}
+
+	providerName, ref, ok := parsePRID(wb.PRID)
+	if !ok {
+		return nil
+	}
+	provider, ok := s.providers[providerName]
+	if !ok {
+		// систему отключили в конфигурации после постановки задачи
+		s.lg.Warn("vcs writeback skipped, provider is disabled",
+			slog.String("provider", string(providerName)),
+			slog.String("pr_id", wb.PRID))
+		return nil
+	}
+
+	userIDs := append([]string(nil), wb.AddedReviewerIDs...)
+	if wb.RemovedReviewerID != "" {
+		userIDs = append(userIDs, wb.RemovedReviewerID)
+	}
+	logins, err := s.identities.Logins(ctx, providerName, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var requested, unmapped []string
+	for _, id := range wb.AddedReviewerIDs {
+		if login, ok := logins[id]; ok {
+			requested = append(requested, login)
+		} else {
+			unmapped = append(unmapped, id)
+		}
+	}
+
+	// комментарий - последним, с ключом задачи: при повторе задачи запрос и отзыв ревью
+	// не создают дублей, а комментарий, дошедший до внешней системы, не публикуется снова
+	if err := provider.RequestReviewers(ctx, ref, requested); err != nil {
+		return err
+	}
+	if login, ok := logins[wb.RemovedReviewerID]; ok {
+		if err := provider.RemoveReviewer(ctx, ref, login); err != nil {
+			return err
+		}
+	}
+	if err := provider.PostComment(ctx, ref, "writeback:"+job.ID, writebackComment(providerName, wb, logins, unmapped)); err != nil {
+		return err
+	}
+
+	s.lg.Info("reviewers written back",
+		slog.String("provider", string(providerName)),
+		slog.String("pr_id", wb.PRID),
+		slog.Any("requested", requested),
+		slog.Any("unmapped", unmapped))
+	return nil
+}
+
+// writebackComment объясняет решение сервиса в обсуждении PR
+func writebackComment(provider domain.VCSProvider, wb domain.VCSWriteback, logins map[string]string, unmapped []string) string {
+	mention := func(userID string) string {
+		if login, ok := logins[userID]; ok {
+			return "@" + login
+		}
+		return "`" + userID + "`"
+	}
+	mentions := func(userIDs []string) string {
+		names := make([]string, len(userIDs))
+		for i, id := range userIDs {
+			names[i] = mention(id)
+		}
+		return strings.Join(names, ", ")
+	}
+
+	var b strings.Builder
+	switch {
+	case wb.RemovedReviewerID != "" && len(wb.AddedReviewerIDs) > 0:
+		fmt.Fprintf(&b, "Reviewer service replaced %s with %s (reason: %s).",
+			mention(wb.RemovedReviewerID), mentions(wb.AddedReviewerIDs), wb.Reason)
+	case wb.RemovedReviewerID != "":
+		fmt.Fprintf(&b, "Reviewer service removed %s without a replacement (reason: %s).", mention(wb.RemovedReviewerID), wb.Reason)
+	case len(wb.AddedReviewerIDs) > 0:
+		fmt.Fprintf(&b, "Reviewer service assigned %s.", mentions(wb.AddedReviewerIDs))
+	default:
+		b.WriteString("Reviewer service found no active reviewers in the author's team.")
+	}
+
+	if len(unmapped) > 0 {
+		fmt.Fprintf(&b, "\n\nNo %s login is mapped for %s, so their review was not requested here.", provider, mentions(unmapped))
+	}
+	return b.String()
+}
@@ -0,0 +1,216 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+// fakeQueue запоминает поставленные задачи
+type fakeQueue struct {
+	payloads []interface{}
+}
+
+func (q *fakeQueue) Enqueue(_ context.Context, kind string, payload interface{}) (*domain.Job, error) {
+	if kind != WritebackKind {
+		return nil, errors.New("unexpected job kind " + kind)
+	}
+	q.payloads = append(q.payloads, payload)
+	return &domain.Job{Kind: kind}, nil
+}
+
+func (q *fakeQueue) Wake() {}
+
+const testWritebackJobID = "job-1"
+
+func writebackJob(t *testing.T, wb domain.VCSWriteback) *domain.Job {
+	t.Helper()
+
+	payload, err := json.Marshal(wb)
+	if err != nil {
+		t.Fatalf("failed to marshal writeback: %v", err)
+	}
+	return &domain.Job{ID: testWritebackJobID, Kind: WritebackKind, Payload: payload}
+}
+
+var writebackLogins = map[string]string{
+	"hubot":    "u2",
+	"monalisa": "u3",
+}
+
+func TestWritebackHandle(t *testing.T) {
+	github := PRRef{Repo: "octo-org/hello-world", Number: 42}
+
+	tests := []struct {
+		name      string
+		wb        domain.VCSWriteback
+		wantCalls []FakeCall
+	}{
+		{
+			name: "created with mapped reviewers",
+			wb:   domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u2", "u3"}, Reason: "CREATED"},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github, Logins: []string{"hubot", "monalisa"}},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service assigned @hubot, @monalisa."},
+			},
+		},
+		{
+			name: "created with unmapped reviewer",
+			wb:   domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u2", "u4"}, Reason: "CREATED"},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github, Logins: []string{"hubot"}},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service assigned @hubot, `u4`." +
+					"\n\nNo github login is mapped for `u4`, so their review was not requested here."},
+			},
+		},
+		{
+			name: "created without reviewers",
+			wb:   domain.VCSWriteback{PRID: githubTestPRID, Reason: "CREATED"},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service found no active reviewers in the author's team."},
+			},
+		},
+		{
+			name: "replaced reviewer is removed",
+			wb: domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u3"}, RemovedReviewerID: "u2",
+				Reason: string(domain.ReassignReasonManual)},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github, Logins: []string{"monalisa"}},
+				{Method: "RemoveReviewer", PR: github, Logins: []string{"hubot"}},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service replaced @hubot with @monalisa (reason: MANUAL)."},
+			},
+		},
+		{
+			name: "unmapped replaced reviewer",
+			wb: domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u2"}, RemovedReviewerID: "u5",
+				Reason: string(domain.ReassignReasonDeactivation)},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github, Logins: []string{"hubot"}},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service replaced `u5` with @hubot (reason: DEACTIVATION)."},
+			},
+		},
+		{
+			name: "removed without replacement",
+			wb: domain.VCSWriteback{PRID: githubTestPRID, RemovedReviewerID: "u2",
+				Reason: string(domain.ReassignReasonDeactivation)},
+			wantCalls: []FakeCall{
+				{Method: "RequestReviewers", PR: github},
+				{Method: "RemoveReviewer", PR: github, Logins: []string{"hubot"}},
+				{Method: "PostComment", PR: github, Key: "writeback:" + testWritebackJobID, Body: "Reviewer service removed @hubot without a replacement (reason: DEACTIVATION)."},
+			},
+		},
+		{
+			name: "PR created through the API",
+			wb:   domain.VCSWriteback{PRID: "pr-1001", AddedReviewerIDs: []string{"u2"}, Reason: "CREATED"},
+		},
+		{
+			name: "provider is disabled",
+			wb:   domain.VCSWriteback{PRID: gitlabTestPRID, AddedReviewerIDs: []string{"u2"}, Reason: "CREATED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewFakeProvider(domain.VCSGitHub, discardLogger())
+			s := NewWritebackService(newIdentities(domain.VCSGitHub, writebackLogins), &fakeQueue{}, discardLogger(), provider)
+
+			if err := s.Handle(context.Background(), writebackJob(t, tt.wb)); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+			if calls := provider.Calls(); !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %+v\nwant %+v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWritebackHandleRetriesAfterCommentFailure(t *testing.T) {
+	provider := NewFakeProvider(domain.VCSGitHub, discardLogger())
+	s := NewWritebackService(newIdentities(domain.VCSGitHub, writebackLogins), &fakeQueue{}, discardLogger(), provider)
+	job := writebackJob(t, domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u3"}, RemovedReviewerID: "u2",
+		Reason: string(domain.ReassignReasonManual)})
+
+	errUnavailable := errors.New("github: 502 Bad Gateway")
+	provider.FailNext("PostComment", errUnavailable)
+
+	// ошибка возвращается очереди задач, и она повторит задачу
+	if err := s.Handle(context.Background(), job); !errors.Is(err, errUnavailable) {
+		t.Fatalf("first Handle() error = %v, want %v", err, errUnavailable)
+	}
+	firstAttempt := provider.Calls()
+	if len(firstAttempt) != 3 || firstAttempt[2].Method != "PostComment" || firstAttempt[2].Err == nil {
+		t.Fatalf("first attempt calls = %+v, want a failed PostComment last", firstAttempt)
+	}
+
+	if err := s.Handle(context.Background(), job); err != nil {
+		t.Fatalf("retried Handle() error = %v", err)
+	}
+
+	// повтор выполняет те же идемпотентные запросы и публикует комментарий один раз
+	calls := provider.Calls()
+	retry := calls[len(firstAttempt):]
+	if len(retry) != 3 {
+		t.Fatalf("retry calls = %+v, want 3 calls", retry)
+	}
+	for i := range 2 {
+		if !reflect.DeepEqual(retry[i], firstAttempt[i]) {
+			t.Errorf("retry call %d = %+v, want %+v", i, retry[i], firstAttempt[i])
+		}
+	}
+
+	var posted []string
+	for _, call := range calls {
+		if call.Method == "PostComment" && call.Err == nil {
+			posted = append(posted, call.Body)
+		}
+	}
+	want := []string{"Reviewer service replaced @hubot with @monalisa (reason: MANUAL)."}
+	if !slices.Equal(posted, want) {
+		t.Errorf("posted comments = %q, want %q", posted, want)
+	}
+}
+
+func TestWritebackHandleRetryAfterLostCommentResponse(t *testing.T) {
+	provider := NewFakeProvider(domain.VCSGitHub, discardLogger())
+	s := NewWritebackService(newIdentities(domain.VCSGitHub, writebackLogins), &fakeQueue{}, discardLogger(), provider)
+	job := writebackJob(t, domain.VCSWriteback{PRID: githubTestPRID, AddedReviewerIDs: []string{"u2"}, Reason: "CREATED"})
+
+	// GitHub оставил комментарий, но ответ не дошел: задача считается упавшей
+	errTimeout := errors.New("github: context deadline exceeded")
+	provider.LoseNextResponse("PostComment", errTimeout)
+	if err := s.Handle(context.Background(), job); !errors.Is(err, errTimeout) {
+		t.Fatalf("first Handle() error = %v, want %v", err, errTimeout)
+	}
+	if err := s.Handle(context.Background(), job); err != nil {
+		t.Fatalf("retried Handle() error = %v", err)
+	}
+
+	want := map[string]string{"writeback:" + testWritebackJobID: "Reviewer service assigned @hubot."}
+	if comments := provider.Comments(); !reflect.DeepEqual(comments, want) {
+		t.Errorf("comments = %q, want %q", comments, want)
+	}
+}
+
+func TestWritebackSchedule(t *testing.T) {
+	provider := NewFakeProvider(domain.VCSGitHub, discardLogger())
+	queue := &fakeQueue{}
+	s := NewWritebackService(newIdentities(domain.VCSGitHub, writebackLogins), queue, discardLogger(), provider)
+
+	for _, prID := range []string{githubTestPRID, gitlabTestPRID, "pr-1001"} {
+		if err := s.Schedule(context.Background(), domain.VCSWriteback{PRID: prID, Reason: "CREATED"}); err != nil {
+			t.Fatalf("Schedule(%s) error = %v", prID, err)
+		}
+	}
+
+	// PR отключенного GitLab и PR, созданные через API, в очередь не попадают
+	want := []interface{}{domain.VCSWriteback{PRID: githubTestPRID, Reason: "CREATED"}}
+	if !reflect.DeepEqual(queue.payloads, want) {
+		t.Errorf("queued = %+v, want %+v", queue.payloads, want)
+	}
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+)
+
+// сколько байт ответа подписчика сохранять в журнал при ошибке
+const maxErrorBodyBytes = 512
+
+// Deliver - обработчик задач DeliveryKind общей очереди: отправляет подписанный запрос
+// и записывает попытку в журнал. Ошибка или ответ не 2xx приводят к повтору задачи
+func (s *WebhookService) Deliver(ctx context.Context, job *domain.Job) error {
+	var p deliveryJob
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("invalid delivery job payload: %w", err)
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, p.DeliveryID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// подписку удалили вместе с доставками
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Status == domain.WebhookDeliveryDelivered || delivery.Status == domain.WebhookDeliveryCancelled {
+		return nil
+	}
+
+	sub, err := s.repo.GetSubscription(ctx, delivery.SubscriptionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !sub.IsActive {
+		return s.repo.SetDeliveryStatus(ctx, delivery.ID, domain.WebhookDeliveryCancelled)
+	}
+
+	log := s.lg.With(
+		slog.Int64("delivery_id", delivery.ID),
+		slog.String("webhook_id", sub.ID),
+		slog.String("event", string(delivery.EventType)),
+		slog.Int("attempt", job.Attempts))
+
+	attempt, sendErr := s.send(ctx, sub, delivery)
+	attempt.Attempt = job.Attempts
+	if err := s.repo.AddAttempt(context.WithoutCancel(ctx), delivery.ID, attempt); err != nil {
+		log.Error("failed to record webhook attempt", slog.Any("error", err))
+	}
+
+	if sendErr == nil {
+		log.Info("webhook delivered", slog.Int("status_code", attempt.StatusCode))
+		return s.repo.SetDeliveryStatus(ctx, delivery.ID, domain.WebhookDeliveryDelivered)
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		if err := s.repo.SetDeliveryStatus(context.WithoutCancel(ctx), delivery.ID, domain.WebhookDeliveryFailed); err != nil {
+			log.Error("failed to mark webhook delivery failed", slog.Any("error", err))
+		}
+	}
+	log.Warn("webhook delivery failed", slog.Any("error", sendErr))
+	return sendErr
+}
+
+// send отправляет подписанный запрос и возвращает попытку для журнала
+func (s *WebhookService) send(ctx context.Context, sub *domain.WebhookSubscription, delivery *domain.WebhookDelivery) (domain.WebhookAttempt, error) {
+	var attempt domain.WebhookAttempt
+
+	body, err := json.Marshal(domain.WebhookBody{
+		DeliveryID: delivery.ID,
+		Event:      delivery.EventType,
+		OccurredAt: delivery.CreatedAt,
+		Data:       delivery.Payload,
+	})
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "reviewer-service-webhooks")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return attempt, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	attempt.Error = fmt.Sprintf("%v: %s", err, bytes.TrimSpace(respBody))
+	return attempt, err
+}
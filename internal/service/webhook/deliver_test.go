@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+func TestDeliverRetriesAfterServerError(t *testing.T) {
+	stub := newStubReceiver(t, http.StatusServiceUnavailable)
+	s, repo, deliveryID := newTestService(t, stub.URL, "subscriber-secret", &bytes.Buffer{})
+	ctx := context.Background()
+
+	// ошибка возвращается очереди задач, и она повторит доставку
+	if err := s.Deliver(ctx, deliveryJobFor(t, deliveryID, 1, 5)); err == nil {
+		t.Fatalf("first Deliver() error = nil, want error for 503")
+	}
+	delivery, _ := repo.GetDelivery(ctx, deliveryID)
+	if delivery.Status != domain.WebhookDeliveryPending {
+		t.Fatalf("status after 503 = %s, want %s", delivery.Status, domain.WebhookDeliveryPending)
+	}
+
+	if err := s.Deliver(ctx, deliveryJobFor(t, deliveryID, 2, 5)); err != nil {
+		t.Fatalf("retried Deliver() error = %v", err)
+	}
+
+	delivery, _ = repo.GetDelivery(ctx, deliveryID)
+	if delivery.Status != domain.WebhookDeliveryDelivered {
+		t.Errorf("status after retry = %s, want %s", delivery.Status, domain.WebhookDeliveryDelivered)
+	}
+	if len(delivery.Attempts) != 2 {
+		t.Fatalf("attempts = %+v, want 2", delivery.Attempts)
+	}
+	failed, succeeded := delivery.Attempts[0], delivery.Attempts[1]
+	if failed.Attempt != 1 || failed.StatusCode != http.StatusServiceUnavailable || !strings.Contains(failed.Error, "upstream is down") {
+		t.Errorf("first attempt = %+v, want 503 with response body", failed)
+	}
+	if succeeded.Attempt != 2 || succeeded.StatusCode != http.StatusOK || succeeded.Error != "" {
+		t.Errorf("second attempt = %+v, want 200 without error", succeeded)
+	}
+
+	// повтор - та же доставка: подписчик может отбросить дубль по X-Webhook-Delivery
+	requests := stub.received()
+	if len(requests) != 2 {
+		t.Fatalf("stub received %d requests, want 2", len(requests))
+	}
+	for i, req := range requests {
+		if got := req.header.Get(HeaderDelivery); got != strconv.FormatInt(deliveryID, 10) {
+			t.Errorf("request %d %s = %q, want %d", i, HeaderDelivery, got, deliveryID)
+		}
+	}
+	if !bytes.Equal(requests[0].body, requests[1].body) {
+		t.Errorf("retried body differs:\n%s\n%s", requests[0].body, requests[1].body)
+	}
+}
+
+func TestDeliverFailsAfterLastAttempt(t *testing.T) {
+	stub := newStubReceiver(t, http.StatusBadGateway)
+	s, repo, deliveryID := newTestService(t, stub.URL, "subscriber-secret", &bytes.Buffer{})
+	ctx := context.Background()
+
+	if err := s.Deliver(ctx, deliveryJobFor(t, deliveryID, 5, 5)); err == nil {
+		t.Fatalf("Deliver() error = nil, want error for 502")
+	}
+	delivery, _ := repo.GetDelivery(ctx, deliveryID)
+	if delivery.Status != domain.WebhookDeliveryFailed {
+		t.Errorf("status = %s, want %s", delivery.Status, domain.WebhookDeliveryFailed)
+	}
+}
+
+func TestDeliverLogsSuccessfulDelivery(t *testing.T) {
+	stub := newStubReceiver(t)
+	var logs bytes.Buffer
+	s, repo, deliveryID := newTestService(t, stub.URL, "subscriber-secret", &logs)
+	ctx := context.Background()
+
+	if err := s.Deliver(ctx, deliveryJobFor(t, deliveryID, 1, 5)); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	// попытка записана в журнал доставок подписки
+	resp, err := s.Deliveries(ctx, "wh-1", 0)
+	if err != nil {
+		t.Fatalf("Deliveries() error = %v", err)
+	}
+	if len(resp.Deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want 1", resp.Deliveries)
+	}
+	delivery := resp.Deliveries[0]
+	if delivery.Status != domain.WebhookDeliveryDelivered {
+		t.Errorf("status = %s, want %s", delivery.Status, domain.WebhookDeliveryDelivered)
+	}
+	if len(delivery.Attempts) != 1 || delivery.Attempts[0].Attempt != 1 || delivery.Attempts[0].StatusCode != http.StatusOK {
+		t.Errorf("attempts = %+v, want one successful attempt", delivery.Attempts)
+	}
+
+	if !strings.Contains(logs.String(), `"msg":"webhook delivered"`) || !strings.Contains(logs.String(), `"status_code":200`) {
+		t.Errorf("log does not record the delivery:\n%s", logs.String())
+	}
+
+	// доставленное событие повторно не отправляется
+	if err := s.Deliver(ctx, deliveryJobFor(t, deliveryID, 2, 5)); err != nil {
+		t.Fatalf("repeated Deliver() error = %v", err)
+	}
+	if n := len(stub.received()); n != 1 {
+		t.Errorf("stub received %d requests, want 1", n)
+	}
+	if d, _ := repo.GetDelivery(ctx, deliveryID); len(d.Attempts) != 1 {
+		t.Errorf("attempts after repeated Deliver = %d, want 1", len(d.Attempts))
+	}
+}
@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+)
+
+// fakeRepo - WebhookRepository в памяти, нужен для Deliver: подписки, доставки и журнал попыток
+type fakeRepo struct {
+	subs       map[string]*domain.WebhookSubscription
+	deliveries map[int64]*domain.WebhookDelivery
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		subs:       make(map[string]*domain.WebhookSubscription),
+		deliveries: make(map[int64]*domain.WebhookDelivery),
+	}
+}
+
+func (r *fakeRepo) CreateSubscription(_ context.Context, url, secret string, eventTypes []domain.WebhookEventType) (*domain.WebhookSubscription, error) {
+	sub := &domain.WebhookSubscription{
+		ID:         "wh-" + strconv.Itoa(len(r.subs)+1),
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		IsActive:   true,
+		CreatedAt:  time.Now(),
+	}
+	r.subs[sub.ID] = sub
+	copied := *sub
+	return &copied, nil
+}
+
+func (r *fakeRepo) GetSubscription(_ context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (r *fakeRepo) ListSubscriptions(context.Context) ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	for _, sub := range r.subs {
+		subs = append(subs, *sub)
+	}
+	return subs, nil
+}
+
+func (r *fakeRepo) ActiveSubscriptionsFor(_ context.Context, eventType domain.WebhookEventType) ([]domain.WebhookSubscription, error) {
+	var subs []domain.WebhookSubscription
+	for _, sub := range r.subs {
+		for _, t := range sub.EventTypes {
+			if sub.IsActive && t == eventType {
+				subs = append(subs, *sub)
+				break
+			}
+		}
+	}
+	return subs, nil
+}
+
+func (r *fakeRepo) DeleteSubscription(_ context.Context, id string) (bool, error) {
+	_, ok := r.subs[id]
+	delete(r.subs, id)
+	return ok, nil
+}
+
+func (r *fakeRepo) CreateDelivery(_ context.Context, subscriptionID string, eventID *int64, _ int,
+	eventType domain.WebhookEventType, payload []byte) (int64, bool, error) {
+	id := int64(len(r.deliveries) + 1)
+	r.deliveries[id] = &domain.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		EventType:      eventType,
+		Payload:        payload,
+		Status:         domain.WebhookDeliveryPending,
+		CreatedAt:      time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC),
+	}
+	return id, true, nil
+}
+
+func (r *fakeRepo) GetDelivery(_ context.Context, id int64) (*domain.WebhookDelivery, error) {
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (r *fakeRepo) SetDeliveryStatus(_ context.Context, id int64, status domain.WebhookDeliveryStatus) error {
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delivery.Status = status
+	return nil
+}
+
+func (r *fakeRepo) AddAttempt(_ context.Context, deliveryID int64, attempt domain.WebhookAttempt) error {
+	delivery, ok := r.deliveries[deliveryID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	return nil
+}
+
+func (r *fakeRepo) ListDeliveries(_ context.Context, subscriptionID string, _ int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// stubRequest - запрос, полученный заглушкой подписчика
+type stubRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// stubReceiver - локальный подписчик: запоминает запросы и отвечает кодами из statuses по очереди,
+// после их окончания - 200
+type stubReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []stubRequest
+}
+
+func newStubReceiver(t *testing.T, statuses ...int) *stubReceiver {
+	t.Helper()
+
+	stub := &stubReceiver{statuses: statuses}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, stubRequest{header: r.Header.Clone(), body: body.Bytes()})
+		status := http.StatusOK
+		if len(stub.statuses) > 0 {
+			status, stub.statuses = stub.statuses[0], stub.statuses[1:]
+		}
+		stub.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status >= http.StatusInternalServerError {
+			_, _ = w.Write([]byte("upstream is down"))
+		}
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *stubReceiver) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+// newTestService создает сервис с подпиской на url и доставкой события pr.merged этой подписке
+func newTestService(t *testing.T, url, secret string, logs *bytes.Buffer) (*WebhookService, *fakeRepo, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	repo := newFakeRepo()
+	sub, err := repo.CreateSubscription(ctx, url, secret, []domain.WebhookEventType{domain.WebhookPRMerged})
+	if err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	deliveryID, _, err := repo.CreateDelivery(ctx, sub.ID, nil, 0, domain.WebhookPRMerged,
+		[]byte(`{"pull_request_id":"pr-1001"}`))
+	if err != nil {
+		t.Fatalf("failed to create delivery: %v", err)
+	}
+
+	lg := slog.New(slog.NewJSONHandler(logs, nil))
+	return NewWebhookService(repo, nil, nil, 5*time.Second, lg), repo, deliveryID
+}
+
+func deliveryJobFor(t *testing.T, deliveryID int64, attempt, maxAttempts int) *domain.Job {
+	t.Helper()
+
+	payload, err := json.Marshal(deliveryJob{DeliveryID: deliveryID})
+	if err != nil {
+		t.Fatalf("failed to marshal job payload: %v", err)
+	}
+	return &domain.Job{Kind: DeliveryKind, Payload: payload, Attempts: attempt, MaxAttempts: maxAttempts}
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const signatureTolerance = 5 * time.Minute
+
+// Подписчик проверяет настоящий запрос Deliver тем же Verify, что и webhook-stub
+func TestSignVerifyRoundTrip(t *testing.T) {
+	stub := newStubReceiver(t)
+	s, _, deliveryID := newTestService(t, stub.URL, "subscriber-secret", &bytes.Buffer{})
+
+	if err := s.Deliver(context.Background(), deliveryJobFor(t, deliveryID, 1, 5)); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	requests := stub.received()
+	if len(requests) != 1 {
+		t.Fatalf("stub received %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	timestamp := req.header.Get(HeaderTimestamp)
+	signature := req.header.Get(HeaderSignature)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		want   bool
+	}{
+		{name: "delivered request", secret: "subscriber-secret", body: req.body, want: true},
+		{name: "wrong secret", secret: "another-secret", body: req.body},
+		{name: "tampered body", secret: "subscriber-secret", body: bytes.Replace(req.body, []byte("pr-1001"), []byte("pr-1002"), 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, timestamp, signature, tt.body, signatureTolerance, now); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "subscriber-secret"
+	body := []byte(`{"event":"ping"}`)
+	now := time.Unix(1763632800, 0)
+	ts := now.Unix()
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		want      bool
+	}{
+		{name: "valid", timestamp: strconv.FormatInt(ts, 10), signature: Sign(secret, ts, body), want: true},
+		{name: "timestamp within tolerance", timestamp: strconv.FormatInt(ts-60, 10), signature: Sign(secret, ts-60, body), want: true},
+		// повтор перехваченного запроса с прежней подписью
+		{name: "stale timestamp", timestamp: strconv.FormatInt(ts-600, 10), signature: Sign(secret, ts-600, body)},
+		{name: "timestamp from the future", timestamp: strconv.FormatInt(ts+600, 10), signature: Sign(secret, ts+600, body)},
+		// метка времени входит в подпись
+		{name: "replaced timestamp", timestamp: strconv.FormatInt(ts-1, 10), signature: Sign(secret, ts, body)},
+		{name: "invalid timestamp", timestamp: "yesterday", signature: Sign(secret, ts, body)},
+		{name: "missing prefix", timestamp: strconv.FormatInt(ts, 10), signature: Sign(secret, ts, body)[len(signaturePrefix):]},
+		{name: "missing signature", timestamp: strconv.FormatInt(ts, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(secret, tt.timestamp, tt.signature, body, signatureTolerance, now); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+)
+
+const SinkName = "webhook"
+
+// webhookEvent - событие подписчика, полученное из события outbox
+type webhookEvent struct {
+	Type domain.WebhookEventType
+	Data json.RawMessage
+}
+
+// Sink - получатель событий outbox, создающий доставки подписчикам
+type Sink struct {
+	svc *WebhookService
+}
+
+func (s *WebhookService) Sink() *Sink {
+	return &Sink{svc: s}
+}
+
+func (k *Sink) Name() string {
+	return SinkName
+}
+
+// Publish создает доставки события всем активным подпискам с подходящим фильтром и ставит их в очередь.
+// Повторная публикация того же события outbox новых доставок не создает
+func (k *Sink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	events, err := toWebhookEvents(event)
+	if err != nil {
+		return err
+	}
+
+	return k.svc.txManager.Do(ctx, func(txCtx context.Context) error {
+		for seq, e := range events {
+			subs, err := k.svc.repo.ActiveSubscriptionsFor(txCtx, e.Type)
+			if err != nil {
+				return err
+			}
+
+			for _, sub := range subs {
+				deliveryID, created, err := k.svc.repo.CreateDelivery(txCtx, sub.ID, &event.ID, seq, e.Type, e.Data)
+				if err != nil {
+					return err
+				}
+				if !created {
+					continue
+				}
+				if _, err := k.svc.queue.Enqueue(txCtx, DeliveryKind, deliveryJob{DeliveryID: deliveryID}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// toWebhookEvents переводит событие outbox в события подписчиков:
+// создание PR дает pr.created и reviewer.assigned на каждого ревьюера,
+// переназначение - reviewer.replaced и reviewer.assigned на нового ревьюера, если он есть,
+// добавление ревьюера - reviewer.assigned
+func toWebhookEvents(event domain.OutboxEvent) ([]webhookEvent, error) {
+	switch event.Type {
+	case domain.EventPRCreated:
+		var payload domain.PREvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil || payload.PullRequest == nil {
+			return nil, fmt.Errorf("invalid %s payload: %v", event.Type, err)
+		}
+
+		events := []webhookEvent{{Type: domain.WebhookPRCreated, Data: event.Payload}}
+		for _, reviewerID := range payload.PullRequest.AssignedReviewers {
+			data, err := json.Marshal(domain.ReviewerAssignedWebhook{
+				PRID:       payload.PullRequest.ID,
+				ReviewerID: reviewerID,
+				Reason:     "CREATED",
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, webhookEvent{Type: domain.WebhookReviewerAssigned, Data: data})
+		}
+		return events, nil
+
+	case domain.EventPRMerged:
+		return []webhookEvent{{Type: domain.WebhookPRMerged, Data: event.Payload}}, nil
+
+	case domain.EventReviewerReassigned:
+		var payload domain.ReviewerReassignedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+
+		events := []webhookEvent{{Type: domain.WebhookReviewerReplaced, Data: event.Payload}}
+		if payload.NewReviewerID != "" {
+			data, err := json.Marshal(domain.ReviewerAssignedWebhook{
+				PRID:       payload.PRID,
+				ReviewerID: payload.NewReviewerID,
+				Reason:     string(payload.Reason),
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, webhookEvent{Type: domain.WebhookReviewerAssigned, Data: data})
+		}
+		return events, nil
+
+	case domain.EventReviewerAssigned:
+		var payload domain.ReviewerAssignedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+
+		data, err := json.Marshal(domain.ReviewerAssignedWebhook{
+			PRID:       payload.PRID,
+			ReviewerID: payload.ReviewerID,
+			Reason:     string(payload.Reason),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []webhookEvent{{Type: domain.WebhookReviewerAssigned, Data: data}}, nil
+
+	case domain.EventUserDeactivated:
+		return []webhookEvent{{Type: domain.WebhookUserDeactivated, Data: event.Payload}}, nil
+	}
+
+	return nil, nil
+}
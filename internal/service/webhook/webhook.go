@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"ynastt/avito_test_task_backend_2025/internal/domain"
+	"ynastt/avito_test_task_backend_2025/internal/repository"
+	"ynastt/avito_test_task_backend_2025/pkg/database"
+)
+
+// DeliveryKind - вид задачи доставки вебхука в общей очереди
+const DeliveryKind = "webhook_delivery"
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, url, secret string, eventTypes []domain.WebhookEventType) (*domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	ActiveSubscriptionsFor(ctx context.Context, eventType domain.WebhookEventType) ([]domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) (bool, error)
+	CreateDelivery(ctx context.Context, subscriptionID string, eventID *int64, eventSeq int,
+		eventType domain.WebhookEventType, payload []byte) (int64, bool, error)
+	GetDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error)
+	SetDeliveryStatus(ctx context.Context, id int64, status domain.WebhookDeliveryStatus) error
+	AddAttempt(ctx context.Context, deliveryID int64, attempt domain.WebhookAttempt) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]domain.WebhookDelivery, error)
+}
+
+type JobQueue interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) (*domain.Job, error)
+	Wake()
+}
+
+// payload задачи DeliveryKind
+type deliveryJob struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// WebhookService управляет подписками и доставляет события подписчикам.
+// Доставки создаются из событий outbox (см. Sink) и выполняются задачами общей очереди,
+// которая отвечает за повторы и dead-letter
+type WebhookService struct {
+	repo      WebhookRepository
+	queue     JobQueue
+	txManager database.TransactionManagerInterface
+	client    *http.Client
+	lg        *slog.Logger
+}
+
+// timeout - таймаут одного HTTP-запроса к подписчику
+func NewWebhookService(repo WebhookRepository,
+	queue JobQueue,
+	txManager database.TransactionManagerInterface,
+	timeout time.Duration,
+	lg *slog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:      repo,
+		queue:     queue,
+		txManager: txManager,
+		client: &http.Client{
+			Timeout: timeout,
+			// редирект мог бы увести запрос с зарегистрированного адреса
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		lg: lg,
+	}
+}
+
+// Create регистрирует подписку. Секрет возвращается только в ответе на создание
+func (s *WebhookService) Create(ctx context.Context, req domain.CreateWebhookRequest) (*domain.WebhookSubscription, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, domain.ErrInvalidWebhookURL
+	}
+
+	if len(req.EventTypes) == 0 {
+		return nil, domain.ErrInvalidEventTypes
+	}
+	seen := make(map[domain.WebhookEventType]bool, len(req.EventTypes))
+	eventTypes := make([]domain.WebhookEventType, 0, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		if !t.IsValid() {
+			return nil, domain.ErrInvalidEventTypes
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = newSecret()
+	}
+
+	sub, err := s.repo.CreateSubscription(ctx, req.URL, secret, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	s.lg.Info("webhook registered", slog.String("webhook_id", sub.ID), slog.String("url", sub.URL))
+	return sub, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) (*domain.WebhooksResponse, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	if subs == nil {
+		subs = []domain.WebhookSubscription{}
+	}
+	return &domain.WebhooksResponse{Webhooks: subs}, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, err := s.getSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id string) error {
+	deleted, err := s.repo.DeleteSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrWebhookNotFound
+	}
+	s.lg.Info("webhook deleted", slog.String("webhook_id", id))
+	return nil
+}
+
+// Deliveries возвращает журнал последних доставок подписки
+func (s *WebhookService) Deliveries(ctx context.Context, id string, limit int) (*domain.WebhookDeliveriesResponse, error) {
+	if _, err := s.getSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case limit <= 0:
+		limit = domain.DefaultPageLimit
+	case limit > domain.MaxPageLimit:
+		limit = domain.MaxPageLimit
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []domain.WebhookDelivery{}
+	}
+	return &domain.WebhookDeliveriesResponse{Deliveries: deliveries}, nil
+}
+
+// Ping ставит в очередь тестовую доставку события ping независимо от фильтра подписки
+func (s *WebhookService) Ping(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	if _, err := s.getSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	var deliveryID int64
+	err := s.txManager.Do(ctx, func(txCtx context.Context) error {
+		var err error
+		deliveryID, _, err = s.repo.CreateDelivery(txCtx, id, nil, 0, domain.WebhookPing, []byte(fmt.Sprintf(`{"webhook_id":%q}`, id)))
+		if err != nil {
+			return err
+		}
+		_, err = s.queue.Enqueue(txCtx, DeliveryKind, deliveryJob{DeliveryID: deliveryID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.queue.Wake()
+
+	return s.repo.GetDelivery(ctx, deliveryID)
+}
+
+func (s *WebhookService) getSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return sub, nil
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,5 @@
+-- created_at участвует в keyset-пагинации, NULL в нем обрывал бы выдачу страниц
+UPDATE pull_requests SET created_at = COALESCE(merged_at, CURRENT_TIMESTAMP) WHERE created_at IS NULL;
+ALTER TABLE pull_requests ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pr_created_at ON pull_requests(created_at, pull_request_id);
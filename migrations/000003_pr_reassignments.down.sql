@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pr_merged_at;
+DROP INDEX IF EXISTS idx_pr_reassignments_created_at;
+DROP INDEX IF EXISTS idx_pr_reassignments_pr_id;
+
+DROP TABLE IF EXISTS pr_reassignments;
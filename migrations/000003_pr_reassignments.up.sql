@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS pr_reassignments (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    old_reviewer_id TEXT NOT NULL,
+    new_reviewer_id TEXT,
+    reason VARCHAR(16) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_reassignments_pr_id ON pr_reassignments(pull_request_id);
+CREATE INDEX IF NOT EXISTS idx_pr_reassignments_created_at ON pr_reassignments(created_at);
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests(merged_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS deactivation_job_items;
+DROP TABLE IF EXISTS deactivation_jobs;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS deactivation_jobs (
+    id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_deactivation_jobs_status ON deactivation_jobs(status, created_at);
+
+CREATE TABLE IF NOT EXISTS deactivation_job_items (
+    job_id TEXT NOT NULL REFERENCES deactivation_jobs(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    user_id TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    error TEXT,
+    pull_requests_info JSONB,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (job_id, position),
+    UNIQUE (job_id, user_id)
+);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+    kind VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    last_error TEXT,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by TEXT,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
+
+-- очередь готовых к запуску задач и задачи с истекшей арендой
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_run_at ON jobs(run_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_jobs_running_locked_until ON jobs(locked_until) WHERE status = 'RUNNING';
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs(created_at);
+
+-- задачи массовой деактивации теперь выполняются общей очередью с тем же id
+INSERT INTO jobs (id, kind, status, max_attempts, created_at, started_at, finished_at)
+SELECT id,
+       'bulk_deactivation',
+       CASE WHEN status = 'COMPLETED' THEN 'SUCCEEDED' ELSE 'PENDING' END,
+       5,
+       created_at,
+       started_at,
+       finished_at
+FROM deactivation_jobs
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,11 @@
+-- логины во внешних системах (GitHub, GitLab), сопоставленные пользователям сервиса
+CREATE TABLE IF NOT EXISTS vcs_identities (
+    provider VARCHAR(16) NOT NULL,
+    -- хранится в нижнем регистре: логины в GitHub и GitLab не различают регистр
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_vcs_identities_user_id ON vcs_identities(user_id);
@@ -0,0 +1,6 @@
+-- CODEOWNERS команды: владельцы путей для выбора ревьюеров
+CREATE TABLE IF NOT EXISTS team_codeowners (
+    team_name TEXT PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
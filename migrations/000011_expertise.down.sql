@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS required_areas;
+ALTER TABLE users DROP COLUMN IF EXISTS skills;
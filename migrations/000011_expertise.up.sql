@@ -0,0 +1,3 @@
+-- навыки пользователей и области, которые должны покрыть ревьюеры PR
+ALTER TABLE users ADD COLUMN IF NOT EXISTS skills TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS required_areas TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- история пар автор-ревьюер для стратегии avoid_repeats
+CREATE INDEX IF NOT EXISTS idx_pr_author_created_at ON pull_requests (author_id, created_at);
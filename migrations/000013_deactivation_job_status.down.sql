@@ -0,0 +1,17 @@
+ALTER TABLE deactivation_jobs
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS finished_at TIMESTAMPTZ;
+
+UPDATE deactivation_jobs d
+SET status = CASE j.status
+        WHEN 'SUCCEEDED' THEN 'COMPLETED'
+        WHEN 'DEAD' THEN 'FAILED'
+        ELSE j.status
+    END,
+    started_at = j.started_at,
+    finished_at = j.finished_at
+FROM jobs j
+WHERE j.id = d.id;
+
+CREATE INDEX IF NOT EXISTS idx_deactivation_jobs_status ON deactivation_jobs(status, created_at);
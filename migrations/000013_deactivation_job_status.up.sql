@@ -0,0 +1,8 @@
+-- статус и время выполнения задачи массовой деактивации берутся из jobs,
+-- иначе отмена, retry или истечение аренды через общую очередь оставляли здесь устаревший статус
+DROP INDEX IF EXISTS idx_deactivation_jobs_status;
+
+ALTER TABLE deactivation_jobs
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS started_at,
+    DROP COLUMN IF EXISTS finished_at;
@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS - SQL-миграции, встроенные в бинарник, чтобы не зависеть от рабочего каталога
+//
+//go:embed *.sql
+var FS embed.FS
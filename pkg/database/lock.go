@@ -0,0 +1,33 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WithAdvisoryLock выполняет fn, удерживая сессионную advisory-блокировку Postgres с ключом key.
+// Блокировка ждет, пока ее не отпустит другой процесс, поэтому несколько реплик выполняют fn по очереди
+func WithAdvisoryLock(ctx context.Context, pool *pgxpool.Pool, key int64, fn func() error) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+		return fmt.Errorf("failed to take advisory lock: %w", err)
+	}
+
+	fnErr := fn()
+
+	// блокировка сессионная: если снять ее не удалось, закрываем соединение, чтобы оно не вернулось в пул с ней
+	if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+		_ = conn.Conn().Close(context.Background())
+		return errors.Join(fnErr, fmt.Errorf("failed to release advisory lock: %w", err))
+	}
+
+	return fnErr
+}
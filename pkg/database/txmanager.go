@@ -2,26 +2,28 @@ package database
 
 import (
 	"context"
-	"database/sql"
 
-	trmsql "github.com/avito-tech/go-transaction-manager/drivers/sql/v2"
+	trmpgx "github.com/avito-tech/go-transaction-manager/drivers/pgxv5/v2"
 	"github.com/avito-tech/go-transaction-manager/trm/v2/manager"
+	"github.com/avito-tech/go-transaction-manager/trm/v2/settings"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type DB struct {
-	db     *sql.DB
-	getter *trmsql.CtxGetter
+	pool   *pgxpool.Pool
+	getter *trmpgx.CtxGetter
 }
 
-func NewDB(db *sql.DB) *DB {
+func NewDB(pool *pgxpool.Pool) *DB {
 	return &DB{
-		db:     db,
-		getter: trmsql.DefaultCtxGetter,
+		pool:   pool,
+		getter: trmpgx.DefaultCtxGetter,
 	}
 }
 
-func (db *DB) Conn(ctx context.Context) trmsql.Tr {
-	return db.getter.DefaultTrOrDB(ctx, db.db)
+func (db *DB) Conn(ctx context.Context) trmpgx.Tr {
+	return db.getter.DefaultTrOrDB(ctx, db.pool)
 }
 
 type TransactionManagerInterface interface {
@@ -32,8 +34,8 @@ type TransactionManager struct {
 	manager *manager.Manager
 }
 
-func NewTransactionManager(db *sql.DB) (*TransactionManager, error) {
-	trManager, err := manager.New(trmsql.NewDefaultFactory(db))
+func NewTransactionManager(pool *pgxpool.Pool) (*TransactionManager, error) {
+	trManager, err := manager.New(trmpgx.NewDefaultFactory(pool))
 
 	if err != nil {
 		return nil, err
@@ -45,3 +47,13 @@ func NewTransactionManager(db *sql.DB) (*TransactionManager, error) {
 func (tm *TransactionManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
 	return tm.manager.Do(ctx, fn)
 }
+
+// DoReadOnly выполняет fn в read-only транзакции REPEATABLE READ,
+// чтобы все запросы внутри видели один и тот же снимок данных
+func (tm *TransactionManager) DoReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := trmpgx.MustSettings(settings.Must(), trmpgx.WithTxOptions(pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	}))
+	return tm.manager.DoWithSettings(ctx, s, fn)
+}
@@ -0,0 +1,12 @@
+package retry
+
+import "time"
+
+// Backoff - задержка перед попыткой attempt+1: base * 2^(attempt-1), не больше maxDelay
+func Backoff(attempt int, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,28 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", attempt: 1, want: time.Second},
+		{name: "zero attempt", attempt: 0, want: time.Second},
+		{name: "doubles", attempt: 3, want: 4 * time.Second},
+		{name: "capped", attempt: 10, want: 30 * time.Second},
+		{name: "no overflow", attempt: 200, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Backoff(tt.attempt, time.Second, 30*time.Second); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}